@@ -0,0 +1,66 @@
+// auth/github.go - GitHub OAuth2 provider (not a true OIDC issuer - no "sub"/id_token, so we key by numeric user id)
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"myapp/config"
+)
+
+type GitHubProvider struct {
+	cfg         config.OAuthProviderConfig
+	redirectURI string
+	httpClient  *http.Client
+}
+
+func NewGitHubProvider(cfg config.OAuthProviderConfig, redirectBaseURL string) *GitHubProvider {
+	return &GitHubProvider{
+		cfg:         cfg,
+		redirectURI: fmt.Sprintf("%s/api/v1/oauth/github/callback", redirectBaseURL),
+		httpClient:  http.DefaultClient,
+	}
+}
+
+func (p *GitHubProvider) Name() string { return "github" }
+
+func (p *GitHubProvider) AuthorizeURL(state, codeChallenge string) string {
+	return authorizeURL(p.cfg, p.redirectURI, state, codeChallenge, nil)
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	return exchangeCode(ctx, p.httpClient, p.cfg.TokenURL, p.cfg.ClientID, p.cfg.ClientSecret, p.redirectURI, code, codeVerifier)
+}
+
+// VerifyIDToken is a no-op: GitHub isn't a true OIDC issuer and never
+// returns an id_token (see the file comment above).
+func (p *GitHubProvider) VerifyIDToken(ctx context.Context, token *Token) error {
+	return nil
+}
+
+func (p *GitHubProvider) FetchUserInfo(ctx context.Context, token *Token) (UserInfo, error) {
+	fields, err := fetchUserInfo(ctx, p.httpClient, p.cfg.UserInfoURL, token.AccessToken)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	// GitHub's /user returns a numeric "id", not a "sub" claim.
+	subject := ""
+	if id, ok := fields["id"]; ok {
+		switch v := id.(type) {
+		case float64:
+			subject = strconv.FormatInt(int64(v), 10)
+		case string:
+			subject = v
+		}
+	}
+
+	return UserInfo{
+		Provider: p.Name(),
+		Subject:  subject,
+		Fields:   fields,
+	}, nil
+}
@@ -0,0 +1,70 @@
+// auth/google.go - Google OAuth2/OIDC provider
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"myapp/config"
+)
+
+// googleJWKSURL and googleIssuer are fixed per Google's OIDC discovery
+// document (https://accounts.google.com/.well-known/openid-configuration) -
+// hardcoded rather than discovered at startup since Google, unlike a
+// generic OIDCProvider, isn't configurable to a different issuer anyway.
+const (
+	googleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+	googleIssuer  = "https://accounts.google.com"
+)
+
+type GoogleProvider struct {
+	cfg         config.OAuthProviderConfig
+	redirectURI string
+	httpClient  *http.Client
+	jwks        *jwksClient
+}
+
+func NewGoogleProvider(cfg config.OAuthProviderConfig, redirectBaseURL string) *GoogleProvider {
+	httpClient := http.DefaultClient
+	return &GoogleProvider{
+		cfg:         cfg,
+		redirectURI: fmt.Sprintf("%s/api/v1/oauth/google/callback", redirectBaseURL),
+		httpClient:  httpClient,
+		jwks:        newJWKSClient(httpClient),
+	}
+}
+
+func (p *GoogleProvider) Name() string { return "google" }
+
+func (p *GoogleProvider) AuthorizeURL(state, codeChallenge string) string {
+	return authorizeURL(p.cfg, p.redirectURI, state, codeChallenge, nil)
+}
+
+func (p *GoogleProvider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	return exchangeCode(ctx, p.httpClient, p.cfg.TokenURL, p.cfg.ClientID, p.cfg.ClientSecret, p.redirectURI, code, codeVerifier)
+}
+
+// VerifyIDToken checks token.IDToken's signature against Google's published
+// JWKS and that it was issued by Google for this app's client ID.
+func (p *GoogleProvider) VerifyIDToken(ctx context.Context, token *Token) error {
+	if token.IDToken == "" {
+		return fmt.Errorf("google: token response had no id_token")
+	}
+	_, err := p.jwks.verifyIDToken(ctx, googleJWKSURL, token.IDToken, googleIssuer, p.cfg.ClientID)
+	return err
+}
+
+func (p *GoogleProvider) FetchUserInfo(ctx context.Context, token *Token) (UserInfo, error) {
+	fields, err := fetchUserInfo(ctx, p.httpClient, p.cfg.UserInfoURL, token.AccessToken)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	return UserInfo{
+		Provider: p.Name(),
+		Subject:  fields.GetString("sub"),
+		Fields:   fields,
+	}, nil
+}
@@ -0,0 +1,127 @@
+// auth/jwks_client.go - Fetches and caches a provider's JWKS for ID-token verification
+
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksCacheTTL bounds how long a fetched JWKS is reused before refetching,
+// so a provider's key rotation is picked up without hitting its JWKS
+// endpoint on every single login.
+const jwksCacheTTL = 10 * time.Minute
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwksDocument struct {
+	Keys []jwk `json:"keys"`
+}
+
+type cachedJWKS struct {
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// jwksClient fetches and caches the RSA public keys published at an OIDC
+// provider's JWKS endpoint, keyed by kid, for verifying ID-token
+// signatures. One instance is shared by every provider that needs it.
+type jwksClient struct {
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedJWKS // keyed by jwksURL
+}
+
+func newJWKSClient(httpClient *http.Client) *jwksClient {
+	return &jwksClient{httpClient: httpClient, cache: make(map[string]cachedJWKS)}
+}
+
+func (c *jwksClient) publicKey(ctx context.Context, jwksURL, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	entry, cached := c.cache[jwksURL]
+	c.mu.Unlock()
+
+	if cached && time.Since(entry.fetchedAt) < jwksCacheTTL {
+		if key, ok := entry.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	keys, err := c.fetch(ctx, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.cache[jwksURL] = cachedJWKS{keys: keys, fetchedAt: time.Now()}
+	c.mu.Unlock()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("jwks: no key for kid %q at %s", kid, jwksURL)
+	}
+	return key, nil
+}
+
+func (c *jwksClient) fetch(ctx context.Context, jwksURL string) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwks: fetching %s: %s", jwksURL, resp.Status)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("jwks: decoding %s: %w", jwksURL, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func rsaPublicKeyFromJWK(nEnc, eEnc string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nEnc)
+	if err != nil {
+		return nil, err
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eEnc)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
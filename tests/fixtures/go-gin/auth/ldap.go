@@ -0,0 +1,76 @@
+// auth/ldap.go - LDAP bind LoginProvider
+
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"myapp/config"
+)
+
+// LDAPProvider authenticates by binding to the directory as the user
+// themselves: a successful bind with the supplied password is the
+// directory's own proof the credential is correct, so no password ever
+// needs to be stored or compared locally.
+type LDAPProvider struct {
+	cfg config.LDAPConfig
+}
+
+func NewLDAPProvider(cfg config.LDAPConfig) *LDAPProvider {
+	return &LDAPProvider{cfg: cfg}
+}
+
+func (p *LDAPProvider) Name() string { return "ldap" }
+
+// Login binds as identifier using BindDN, then re-searches BaseDN with
+// UserFilter to pull profile attributes for auto-provisioning - the bind
+// alone only proves the credential, it doesn't return any attributes.
+func (p *LDAPProvider) Login(ctx context.Context, identifier, password string) (UserInfo, error) {
+	addr := fmt.Sprintf("%s:%d", p.cfg.Host, p.cfg.Port)
+
+	var conn *ldap.Conn
+	var err error
+	if p.cfg.UseTLS {
+		conn, err = ldap.DialURL(fmt.Sprintf("ldaps://%s", addr), ldap.DialWithTLSConfig(&tls.Config{ServerName: p.cfg.Host}))
+	} else {
+		conn, err = ldap.DialURL(fmt.Sprintf("ldap://%s", addr))
+	}
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("ldap: dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	userDN := fmt.Sprintf(p.cfg.BindDN, identifier)
+	if err := conn.Bind(userDN, password); err != nil {
+		return UserInfo{}, ErrLoginFailed
+	}
+
+	searchReq := ldap.NewSearchRequest(
+		p.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 1, 0, false,
+		fmt.Sprintf(p.cfg.UserFilter, identifier),
+		[]string{"mail", "givenName", "sn", "uid"},
+		nil,
+	)
+	result, err := conn.SearchWithPaging(searchReq, 1)
+	if err != nil || len(result.Entries) == 0 {
+		return UserInfo{}, ErrLoginFailed
+	}
+	entry := result.Entries[0]
+
+	fields := UserInfoFields{
+		"email":       entry.GetAttributeValue("mail"),
+		"given_name":  entry.GetAttributeValue("givenName"),
+		"family_name": entry.GetAttributeValue("sn"),
+		"username":    entry.GetAttributeValue("uid"),
+	}
+
+	return UserInfo{
+		Provider: p.Name(),
+		Subject:  entry.DN,
+		Fields:   fields,
+	}, nil
+}
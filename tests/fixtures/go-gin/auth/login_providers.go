@@ -0,0 +1,27 @@
+// auth/login_providers.go - Factory for external LoginProvider sources
+
+package auth
+
+import "myapp/config"
+
+// NewLoginProviders builds the enabled LoginProvider sources named in
+// cfg.Priority, in that order - UserService.Authenticate tries them in the
+// returned order after the local password check fails. A name with no
+// matching enabled source is skipped rather than erroring, so an operator
+// can reorder or disable a source with a config change alone.
+func NewLoginProviders(cfg config.ExternalAuthConfig) []LoginProvider {
+	providers := make([]LoginProvider, 0, len(cfg.Priority))
+	for _, name := range cfg.Priority {
+		switch name {
+		case "ldap":
+			if cfg.LDAP.Enabled {
+				providers = append(providers, NewLDAPProvider(cfg.LDAP))
+			}
+		default:
+			if pc, ok := cfg.OIDCROPC[name]; ok && pc.TokenURL != "" {
+				providers = append(providers, NewOIDCPasswordProvider(name, pc))
+			}
+		}
+	}
+	return providers
+}
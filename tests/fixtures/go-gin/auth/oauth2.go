@@ -0,0 +1,132 @@
+// auth/oauth2.go - Shared authorization-code exchange helper for OAuthProvider implementations
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"myapp/config"
+)
+
+// exchangeCode performs a standard RFC 6749 authorization_code grant against
+// tokenURL and decodes the JSON token response. GitHub's endpoint returns
+// form-encoded bodies by default, so the request always sends
+// "Accept: application/json", for every provider. codeVerifier
+// is the RFC 7636 PKCE verifier matching the code_challenge sent to
+// authorizeURL; it's omitted from the request when empty, for providers
+// that don't support PKCE.
+func exchangeCode(ctx context.Context, httpClient *http.Client, tokenURL, clientID, clientSecret, redirectURI, code, codeVerifier string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+	}
+	if codeVerifier != "" {
+		form.Set("code_verifier", codeVerifier)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("token exchange failed: %s: %s", resp.Status, string(body))
+	}
+
+	var raw struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		IDToken      string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding token response: %w", err)
+	}
+
+	token := &Token{
+		AccessToken:  raw.AccessToken,
+		RefreshToken: raw.RefreshToken,
+		IDToken:      raw.IDToken,
+	}
+	if raw.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(raw.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func fetchUserInfo(ctx context.Context, httpClient *http.Client, userInfoURL, accessToken string) (UserInfoFields, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("userinfo request failed: %s: %s", resp.Status, string(body))
+	}
+
+	var fields UserInfoFields
+	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+		return nil, fmt.Errorf("decoding userinfo response: %w", err)
+	}
+	return fields, nil
+}
+
+// authorizeURL builds the provider redirect for the authorization request,
+// always including the PKCE code_challenge (RFC 7636) alongside state so a
+// code intercepted in transit can't be exchanged without the matching
+// verifier - see auth.StateStore, which holds the verifier server-side
+// until Exchange needs it.
+func authorizeURL(cfg config.OAuthProviderConfig, redirectURI, state, codeChallenge string, extra url.Values) string {
+	q := url.Values{
+		"client_id":             {cfg.ClientID},
+		"redirect_uri":          {redirectURI},
+		"response_type":         {"code"},
+		"scope":                 {joinScopes(cfg.Scopes)},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	for k, v := range extra {
+		q[k] = v
+	}
+	return cfg.AuthorizeURL + "?" + q.Encode()
+}
+
+func joinScopes(scopes []string) string {
+	out := ""
+	for i, s := range scopes {
+		if i > 0 {
+			out += " "
+		}
+		out += s
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+// auth/pkce.go - RFC 7636 PKCE verifier/challenge generation
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// generatePKCE returns a high-entropy code_verifier (32 random bytes,
+// base64url-encoded - comfortably within RFC 7636 §4.1's 43-128 char,
+// unreserved-alphabet requirement) and its S256 code_challenge.
+func generatePKCE() (verifier, challenge string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(buf)
+
+	sum := sha256.Sum256([]byte(verifier))
+	challenge = base64.RawURLEncoding.EncodeToString(sum[:])
+	return verifier, challenge, nil
+}
@@ -0,0 +1,60 @@
+// auth/provider.go - Login provider interfaces for password and SSO authentication
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// LoginProvider authenticates a user with a username/password pair.
+// The built-in password flow in service.UserService satisfies this
+// indirectly; it exists so alternative credential stores (LDAP, etc.)
+// can be swapped in without changing callers.
+type LoginProvider interface {
+	Name() string
+	Login(ctx context.Context, identifier, password string) (UserInfo, error)
+}
+
+// ErrLoginFailed is the sentinel every LoginProvider returns for a rejected
+// credential (bad bind, bad password, unknown identifier), as opposed to a
+// transport/config failure. UserService.Authenticate relies on this to move
+// on to the next configured source rather than surfacing the specific
+// reason, which would leak which sources exist.
+var ErrLoginFailed = errors.New("auth: invalid credentials")
+
+// OAuthProvider drives a single-sign-on authorization code flow: building
+// the redirect URL (with a PKCE code_challenge - RFC 7636 - so a stolen
+// authorization code is useless without the matching verifier), exchanging
+// the returned code and verifier for a token, verifying the token's ID
+// token if it has one, and fetching the authenticated user's profile.
+type OAuthProvider interface {
+	Name() string
+	AuthorizeURL(state, codeChallenge string) string
+	Exchange(ctx context.Context, code, codeVerifier string) (*Token, error)
+	// VerifyIDToken checks token.IDToken's signature and claims against the
+	// provider's JWKS, if it issues one. Implementations for providers
+	// that don't (GitHub) are a no-op.
+	VerifyIDToken(ctx context.Context, token *Token) error
+	FetchUserInfo(ctx context.Context, token *Token) (UserInfo, error)
+}
+
+// Token is the subset of an OAuth2 token response providers need to pass
+// along to VerifyIDToken/FetchUserInfo. IDToken is empty for providers
+// that don't return one (GitHub).
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresAt    time.Time
+}
+
+// UserInfo is the normalized result of a login, regardless of provider.
+// Subject is the provider's stable user identifier ("sub" for OIDC); it is
+// combined with Provider to find or create the linked models.User.
+type UserInfo struct {
+	Provider string
+	Subject  string
+	Fields   UserInfoFields
+}
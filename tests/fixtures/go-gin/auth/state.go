@@ -0,0 +1,118 @@
+// auth/state.go - Short-lived state store for the OAuth authorize/callback round trip
+
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StateStore persists the random state value issued at /oauth/:provider/login,
+// along with the PKCE code_verifier (RFC 7636) minted alongside it, so the
+// callback can confirm the redirect actually originated from us and
+// complete the token exchange with the verifier matching the
+// code_challenge sent at the start. The in-memory implementation below is
+// fine for a single instance; a Redis-backed one should be dropped in for
+// multi-instance deployments.
+//
+// GenerateForLink supports the account-linking flow: the target user ID is
+// bound to the state server-side rather than carried in a second
+// client-writable cookie, so the callback can't be tricked into linking an
+// attacker's identity to an arbitrary victim account. Consume reports that
+// binding back so the callback can tell a link attempt from a plain login.
+type StateStore interface {
+	Generate(ttl time.Duration) (state, codeChallenge string, err error)
+	GenerateForLink(ttl time.Duration, userID uuid.UUID) (state, codeChallenge string, err error)
+	Consume(state string) (linkUserID *uuid.UUID, codeVerifier string, ok bool)
+}
+
+type stateEntry struct {
+	expiresAt    time.Time
+	linkUser     *uuid.UUID
+	codeVerifier string
+}
+
+type memoryStateStore struct {
+	mu     sync.Mutex
+	states map[string]stateEntry
+}
+
+func NewMemoryStateStore() StateStore {
+	s := &memoryStateStore{states: make(map[string]stateEntry)}
+	go s.cleanup()
+	return s
+}
+
+func (s *memoryStateStore) Generate(ttl time.Duration) (string, string, error) {
+	return s.generate(ttl, nil)
+}
+
+func (s *memoryStateStore) GenerateForLink(ttl time.Duration, userID uuid.UUID) (string, string, error) {
+	return s.generate(ttl, &userID)
+}
+
+func (s *memoryStateStore) generate(ttl time.Duration, linkUser *uuid.UUID) (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	state := base64.RawURLEncoding.EncodeToString(buf)
+
+	codeVerifier, codeChallenge, err := generatePKCE()
+	if err != nil {
+		return "", "", err
+	}
+
+	s.mu.Lock()
+	s.states[state] = stateEntry{expiresAt: time.Now().Add(ttl), linkUser: linkUser, codeVerifier: codeVerifier}
+	s.mu.Unlock()
+
+	return state, codeChallenge, nil
+}
+
+// Consume validates and burns a one-time state value. The returned
+// linkUserID is non-nil only when the state was minted by GenerateForLink,
+// letting the callback distinguish an account-linking round trip from a
+// plain login without a second, client-writable signal. codeVerifier is
+// the PKCE verifier matching the code_challenge originally sent to the
+// provider, for the callback to pass along to Exchange.
+func (s *memoryStateStore) Consume(state string) (*uuid.UUID, string, bool) {
+	entry, ok := s.consume(state)
+	if !ok {
+		return nil, "", false
+	}
+	return entry.linkUser, entry.codeVerifier, true
+}
+
+func (s *memoryStateStore) consume(state string) (stateEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.states[state]
+	if !ok {
+		return stateEntry{}, false
+	}
+	delete(s.states, state)
+	if time.Now().After(entry.expiresAt) {
+		return stateEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *memoryStateStore) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	for range ticker.C {
+		s.mu.Lock()
+		now := time.Now()
+		for state, entry := range s.states {
+			if now.After(entry.expiresAt) {
+				delete(s.states, state)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,74 @@
+// auth/userinfo.go - Typed accessors over a provider's raw userinfo payload
+
+package auth
+
+import "time"
+
+// UserInfoFields wraps the raw, provider-specific claims/fields returned by
+// an OAuthProvider (OIDC userinfo, GitHub's /user, ...) with typed getters
+// so callers don't sprinkle type assertions across the codebase.
+type UserInfoFields map[string]any
+
+func (f UserInfoFields) GetString(key string) string {
+	if v, ok := f[key]; ok {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// GetStringFromKeysOrEmpty tries each key in order and returns the first
+// non-empty string value, or "" if none match. Useful when providers use
+// different claim names for the same concept (e.g. "name" vs "full_name").
+func (f UserInfoFields) GetStringFromKeysOrEmpty(keys ...string) string {
+	for _, key := range keys {
+		if v := f.GetString(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (f UserInfoFields) GetBoolean(key string) bool {
+	if v, ok := f[key]; ok {
+		switch b := v.(type) {
+		case bool:
+			return b
+		case string:
+			return b == "true" || b == "1"
+		}
+	}
+	return false
+}
+
+// GetNullDate parses an RFC3339 date string, returning nil if the field is
+// absent or unparsable rather than erroring - most providers omit optional
+// profile fields like date of birth entirely.
+func (f UserInfoFields) GetNullDate(key string) *time.Time {
+	s := f.GetString(key)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil
+		}
+	}
+	return &t
+}
+
+// GetNullDateFromKeysOrEmpty tries each key in order and returns the first
+// one that parses as a date, mirroring GetStringFromKeysOrEmpty for
+// providers that name the same claim differently (e.g. "birthdate" vs
+// "date_of_birth").
+func (f UserInfoFields) GetNullDateFromKeysOrEmpty(keys ...string) *time.Time {
+	for _, key := range keys {
+		if t := f.GetNullDate(key); t != nil {
+			return t
+		}
+	}
+	return nil
+}
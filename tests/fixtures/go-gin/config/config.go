@@ -3,17 +3,25 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Auth     AuthConfig
-	Email    EmailConfig
-	Cache    CacheConfig
+	App          AppConfig
+	Database     DatabaseConfig
+	Auth         AuthConfig
+	Email        EmailConfig
+	Cache        CacheConfig
+	OAuth        OAuthConfig
+	RBAC         RBACConfig
+	Maintenance  MaintenanceConfig
+	ExternalAuth ExternalAuthConfig
+	Events       EventsConfig
+	RateLimit    RateLimitConfig
 }
 
 type AppConfig struct {
@@ -39,6 +47,41 @@ type AuthConfig struct {
 	AccessTokenExpiry  int64 // seconds
 	RefreshTokenExpiry int64 // seconds
 	BCryptCost         int
+
+	// SigningKeyRotationInterval is how often a new RSA signing key is
+	// generated for access tokens. SigningKeyRetireAfter is how much longer
+	// a rotated-out key's public half stays published in the JWKS document
+	// and accepted for verification, so tokens issued just before a
+	// rotation don't start failing mid-flight.
+	SigningKeyRotationInterval time.Duration
+	SigningKeyRetireAfter      time.Duration
+
+	// PasswordBreachCheckEnabled turns on the Pwned Passwords k-anonymity
+	// lookup in utils.PasswordPolicy. It defaults to off so offline or
+	// network-restricted environments don't fail password validation on a
+	// dependency they can't reach.
+	PasswordBreachCheckEnabled bool
+	PasswordBreachCheckTimeout time.Duration
+
+	// PasswordMinLength/PasswordBreachCountLimit tune utils.PasswordPolicy's
+	// enforcement: a password shorter than PasswordMinLength is rejected on
+	// top of ValidatePassword's baseline 8-character rule, and a breach
+	// count at or above PasswordBreachCountLimit is treated as pwned.
+	PasswordMinLength        int
+	PasswordBreachCountLimit int
+
+	// OTPEncryptionKey derives the AES-GCM key that encrypts TOTP secrets
+	// at rest. It's independent of JWTSecret so the two can be rotated on
+	// different schedules; left empty, it falls back to deriving from
+	// JWTSecret for environments that haven't set it yet.
+	OTPEncryptionKey string
+
+	// PasswordHashCalibrate turns on the startup bcrypt cost calibration
+	// (utils.CalibrateBcryptCost) targeting PasswordHashTarget; disabled, the
+	// user password hasher is pinned to BCryptCost instead, e.g. for tests
+	// that don't want a calibration pass or non-deterministic hash cost.
+	PasswordHashCalibrate bool
+	PasswordHashTarget    time.Duration
 }
 
 type EmailConfig struct {
@@ -49,12 +92,193 @@ type EmailConfig struct {
 	FromAddress    string
 	SupportAddress string
 	UseTLS         bool
+
+	// DKIMDomain/DKIMSelector/DKIMPrivateKeyPEM configure outbound signing.
+	// DKIMPrivateKeyPEM is left empty to send unsigned, e.g. in development.
+	DKIMDomain        string
+	DKIMSelector      string
+	DKIMPrivateKeyPEM string
+
+	// QueueWorkers/QueueMaxAttempts/QueueBaseBackoff/QueuePollInterval tune
+	// the durable send queue (see email.Queue); all fall back to sane
+	// defaults when left at zero.
+	QueueWorkers      int
+	QueueMaxAttempts  int
+	QueueBaseBackoff  time.Duration
+	QueuePollInterval time.Duration
 }
 
 type CacheConfig struct {
-	RedisURL    string
-	DefaultTTL  time.Duration
-	MaxSize     int
+	RedisURL   string
+	DefaultTTL time.Duration
+	MaxSize    int
+}
+
+// OAuthConfig holds per-provider SSO settings, keyed by provider name
+// (e.g. "google", "github", or a custom name for a generic OIDC issuer).
+type OAuthConfig struct {
+	RedirectBaseURL string
+	FrontendURL     string
+	Providers       map[string]OAuthProviderConfig
+}
+
+// ExternalAuthConfig configures the auth.LoginProvider sources UserService
+// tries, in Priority order, after a user's local password check fails -
+// see auth.NewLoginProviders. A name in Priority with no matching enabled
+// source below is skipped.
+type ExternalAuthConfig struct {
+	Priority []string
+	LDAP     LDAPConfig
+	// OIDCROPC holds one OAuthProviderConfig per trusted first-party client
+	// allowed to use the resource-owner-password-credentials grant, keyed
+	// by provider name. It's kept separate from OAuth.Providers since ROPC
+	// shouldn't be offered to the public redirect-flow clients configured
+	// there.
+	OIDCROPC map[string]OAuthProviderConfig
+}
+
+// LDAPConfig points at a single directory server used to authenticate by
+// binding as the user - see auth.LDAPProvider.
+type LDAPConfig struct {
+	Enabled bool
+	Host    string
+	Port    int
+	UseTLS  bool
+	// BindDN is an fmt.Sprintf template with one %s for the identifier,
+	// e.g. "uid=%s,ou=people,dc=example,dc=com".
+	BindDN string
+	BaseDN string
+	// UserFilter is an fmt.Sprintf template with one %s for the
+	// identifier, e.g. "(uid=%s)", used to look up profile attributes
+	// after a successful bind.
+	UserFilter string
+}
+
+// EventsConfig tunes events.Dispatcher's outbox poll, which republishes
+// events.Outbox-written events to events.Bus's async subscribers.
+type EventsConfig struct {
+	DispatchInterval  time.Duration
+	DispatchBatchSize int
+}
+
+// RateLimitConfig tunes middleware.RedisRateLimiter at startup, mirroring
+// what its runtime setters (SetRouteLimit, SetRouteTokenBucket,
+// SetTrustedProxies, SetFailMode) already accept, so operators can declare
+// the whole policy without a code change.
+type RateLimitConfig struct {
+	DefaultLimit  int
+	DefaultWindow time.Duration
+	// FailMode is "open" (admit requests while Redis is unreachable, the
+	// default) or "closed" (reject them) - see middleware.FailMode.
+	FailMode string
+	// TrustedProxies are CIDRs (or bare IPs, treated as /32) of the
+	// immediate peers allowed to set X-Forwarded-For - see
+	// middleware.RedisRateLimiter.SetTrustedProxies.
+	TrustedProxies []string
+	// Routes overrides the default budget per route path, keyed exactly
+	// as registered with gin (c.FullPath()).
+	Routes map[string]RouteLimitOverride
+}
+
+// RouteLimitOverride is one entry of RateLimitConfig.Routes, decoded from
+// the RATE_LIMIT_ROUTES_JSON env var, e.g.
+// {"/api/v1/login":{"limit":10,"window_seconds":60,"burst":20}}. A zero
+// Burst keeps the sliding-window algorithm; a positive Burst switches that
+// route to the token-bucket algorithm.
+type RouteLimitOverride struct {
+	Limit         int `json:"limit"`
+	WindowSeconds int `json:"window_seconds"`
+	Burst         int `json:"burst"`
+}
+
+// RBACConfig optionally overrides the built-in role->permission mapping, so
+// operators can define custom roles without recompiling. Roles is a JSON
+// object string, e.g. {"admin":["users:read","users:write"]}.
+type RBACConfig struct {
+	Roles map[string][]string
+}
+
+// MaintenanceConfig tunes the maintenance.Scheduler's built-in jobs.
+// UnverifiedRetentionDays/SoftDeleteRetentionDays are in days rather than
+// time.Duration since that's how an operator thinks about retention; Interval
+// is how often the scheduler sweeps all jobs.
+type MaintenanceConfig struct {
+	Enabled                 bool
+	Interval                time.Duration
+	UnverifiedRetentionDays int
+	SoftDeleteRetentionDays int
+}
+
+type OAuthProviderConfig struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	AuthorizeURL string
+	TokenURL     string
+	UserInfoURL  string
+	// IssuerURL is the "iss" a generic OIDC provider's ID tokens must carry -
+	// see auth.OIDCProvider.VerifyIDToken. It isn't discovered, so it must be
+	// set here explicitly.
+	IssuerURL string
+	// JWKSURL is where the provider publishes the public keys its ID
+	// tokens are signed with - see auth.verifyIDToken. Google and GitHub
+	// don't need it set here since GoogleProvider hardcodes Google's
+	// well-known JWKS/issuer and GitHub doesn't issue an ID token at all.
+	JWKSURL string
+
+	// Claims maps local profile fields to the provider's userinfo claim
+	// names, tried in order. A field left empty falls back to
+	// defaultClaimMapping, so a new IdP can be dropped in via config alone
+	// for providers that follow the usual OIDC claim names.
+	Claims ClaimMapping
+}
+
+// ClaimMapping lists, per local profile field, the claim names to try in
+// order against a provider's userinfo payload (see
+// auth.UserInfoFields.GetStringFromKeysOrEmpty).
+type ClaimMapping struct {
+	Email     []string
+	Username  []string
+	FirstName []string
+	LastName  []string
+	Avatar    []string
+	DOB       []string
+}
+
+// defaultClaimMapping covers the claim names used by most OIDC-compliant
+// providers, so only non-standard IdPs need an explicit ClaimMapping.
+var defaultClaimMapping = ClaimMapping{
+	Email:     []string{"email"},
+	Username:  []string{"preferred_username", "nickname", "login", "email"},
+	FirstName: []string{"given_name", "first_name"},
+	LastName:  []string{"family_name", "last_name"},
+	Avatar:    []string{"picture", "avatar_url"},
+	DOB:       []string{"birthdate", "date_of_birth"},
+}
+
+// WithDefaults fills any unset field with defaultClaimMapping, so a provider
+// config only needs to override the claim names that differ from the norm.
+func (m ClaimMapping) WithDefaults() ClaimMapping {
+	merged := defaultClaimMapping
+	if len(m.Email) > 0 {
+		merged.Email = m.Email
+	}
+	if len(m.Username) > 0 {
+		merged.Username = m.Username
+	}
+	if len(m.FirstName) > 0 {
+		merged.FirstName = m.FirstName
+	}
+	if len(m.LastName) > 0 {
+		merged.LastName = m.LastName
+	}
+	if len(m.Avatar) > 0 {
+		merged.Avatar = m.Avatar
+	}
+	if len(m.DOB) > 0 {
+		merged.DOB = m.DOB
+	}
+	return merged
 }
 
 func Load() *Config {
@@ -75,27 +299,158 @@ func Load() *Config {
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:          getEnv("JWT_SECRET", "change-me-in-production"),
-			RefreshSecret:      getEnv("REFRESH_SECRET", "change-me-in-production"),
-			AccessTokenExpiry:  getEnvInt64("ACCESS_TOKEN_EXPIRY", 900),    // 15 minutes
-			RefreshTokenExpiry: getEnvInt64("REFRESH_TOKEN_EXPIRY", 604800), // 7 days
-			BCryptCost:         getEnvInt("BCRYPT_COST", 10),
+			JWTSecret:                  getEnv("JWT_SECRET", "change-me-in-production"),
+			RefreshSecret:              getEnv("REFRESH_SECRET", "change-me-in-production"),
+			AccessTokenExpiry:          getEnvInt64("ACCESS_TOKEN_EXPIRY", 900),     // 15 minutes
+			RefreshTokenExpiry:         getEnvInt64("REFRESH_TOKEN_EXPIRY", 604800), // 7 days
+			BCryptCost:                 getEnvInt("BCRYPT_COST", 10),
+			SigningKeyRotationInterval: time.Duration(getEnvInt("SIGNING_KEY_ROTATION_HOURS", 24)) * time.Hour,
+			SigningKeyRetireAfter:      time.Duration(getEnvInt("SIGNING_KEY_RETIRE_HOURS", 48)) * time.Hour,
+			PasswordBreachCheckEnabled: getEnvBool("PASSWORD_BREACH_CHECK_ENABLED", false),
+			PasswordBreachCheckTimeout: time.Duration(getEnvInt("PASSWORD_BREACH_CHECK_TIMEOUT_SECONDS", 3)) * time.Second,
+			PasswordMinLength:          getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			PasswordBreachCountLimit:   getEnvInt("PASSWORD_BREACH_COUNT_LIMIT", 1),
+			OTPEncryptionKey:           getEnv("OTP_ENCRYPTION_KEY", ""),
+			PasswordHashCalibrate:      getEnvBool("PASSWORD_HASH_CALIBRATE", true),
+			PasswordHashTarget:         time.Duration(getEnvInt("PASSWORD_HASH_TARGET_MS", 250)) * time.Millisecond,
 		},
 		Email: EmailConfig{
-			SMTPHost:       getEnv("SMTP_HOST", "localhost"),
-			SMTPPort:       getEnvInt("SMTP_PORT", 587),
-			Username:       getEnv("SMTP_USERNAME", ""),
-			Password:       getEnv("SMTP_PASSWORD", ""),
-			FromAddress:    getEnv("EMAIL_FROM", "noreply@example.com"),
-			SupportAddress: getEnv("EMAIL_SUPPORT", "support@example.com"),
-			UseTLS:         getEnvBool("SMTP_TLS", true),
+			SMTPHost:          getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:          getEnvInt("SMTP_PORT", 587),
+			Username:          getEnv("SMTP_USERNAME", ""),
+			Password:          getEnv("SMTP_PASSWORD", ""),
+			FromAddress:       getEnv("EMAIL_FROM", "noreply@example.com"),
+			SupportAddress:    getEnv("EMAIL_SUPPORT", "support@example.com"),
+			UseTLS:            getEnvBool("SMTP_TLS", true),
+			DKIMDomain:        getEnv("DKIM_DOMAIN", ""),
+			DKIMSelector:      getEnv("DKIM_SELECTOR", "default"),
+			DKIMPrivateKeyPEM: getEnv("DKIM_PRIVATE_KEY_PEM", ""),
+			QueueWorkers:      getEnvInt("EMAIL_QUEUE_WORKERS", 4),
+			QueueMaxAttempts:  getEnvInt("EMAIL_QUEUE_MAX_ATTEMPTS", 5),
+			QueueBaseBackoff:  time.Duration(getEnvInt("EMAIL_QUEUE_BASE_BACKOFF_SECONDS", 2)) * time.Second,
+			QueuePollInterval: time.Duration(getEnvInt("EMAIL_QUEUE_POLL_INTERVAL_SECONDS", 1)) * time.Second,
 		},
 		Cache: CacheConfig{
 			RedisURL:   getEnv("REDIS_URL", "redis://localhost:6379"),
 			DefaultTTL: time.Duration(getEnvInt("CACHE_TTL", 3600)) * time.Second,
 			MaxSize:    getEnvInt("CACHE_MAX_SIZE", 10000),
 		},
+		OAuth: OAuthConfig{
+			RedirectBaseURL: getEnv("OAUTH_REDIRECT_BASE_URL", getEnv("APP_BASE_URL", "http://localhost:8080")),
+			FrontendURL:     getEnv("OAUTH_FRONTEND_URL", "http://localhost:3000"),
+			Providers: map[string]OAuthProviderConfig{
+				"google": {
+					ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+					ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+					Scopes:       []string{"openid", "email", "profile"},
+					AuthorizeURL: "https://accounts.google.com/o/oauth2/v2/auth",
+					TokenURL:     "https://oauth2.googleapis.com/token",
+					UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+					Claims: ClaimMapping{
+						FirstName: []string{"given_name"},
+						LastName:  []string{"family_name"},
+						Avatar:    []string{"picture"},
+					},
+				},
+				"github": {
+					ClientID:     getEnv("GITHUB_CLIENT_ID", ""),
+					ClientSecret: getEnv("GITHUB_CLIENT_SECRET", ""),
+					Scopes:       []string{"read:user", "user:email"},
+					AuthorizeURL: "https://github.com/login/oauth/authorize",
+					TokenURL:     "https://github.com/login/oauth/access_token",
+					UserInfoURL:  "https://api.github.com/user",
+					Claims: ClaimMapping{
+						Username: []string{"login"},
+						Avatar:   []string{"avatar_url"},
+					},
+				},
+			},
+		},
+		RBAC: RBACConfig{
+			Roles: parseRolesJSON(getEnv("RBAC_ROLES_JSON", "")),
+		},
+		Maintenance: MaintenanceConfig{
+			Enabled:                 getEnvBool("MAINTENANCE_ENABLED", true),
+			Interval:                time.Duration(getEnvInt("MAINTENANCE_INTERVAL_MINUTES", 60)) * time.Minute,
+			UnverifiedRetentionDays: getEnvInt("MAINTENANCE_UNVERIFIED_RETENTION_DAYS", 7),
+			SoftDeleteRetentionDays: getEnvInt("MAINTENANCE_SOFT_DELETE_RETENTION_DAYS", 30),
+		},
+		ExternalAuth: ExternalAuthConfig{
+			Priority: parseCSV(getEnv("EXTERNAL_AUTH_PRIORITY", "")),
+			LDAP: LDAPConfig{
+				Enabled:    getEnvBool("LDAP_ENABLED", false),
+				Host:       getEnv("LDAP_HOST", ""),
+				Port:       getEnvInt("LDAP_PORT", 636),
+				UseTLS:     getEnvBool("LDAP_USE_TLS", true),
+				BindDN:     getEnv("LDAP_BIND_DN_TEMPLATE", ""),
+				BaseDN:     getEnv("LDAP_BASE_DN", ""),
+				UserFilter: getEnv("LDAP_USER_FILTER", "(uid=%s)"),
+			},
+			OIDCROPC: map[string]OAuthProviderConfig{
+				"oidc": {
+					ClientID:     getEnv("OIDC_ROPC_CLIENT_ID", ""),
+					ClientSecret: getEnv("OIDC_ROPC_CLIENT_SECRET", ""),
+					Scopes:       []string{"openid", "email", "profile"},
+					TokenURL:     getEnv("OIDC_ROPC_TOKEN_URL", ""),
+					UserInfoURL:  getEnv("OIDC_ROPC_USERINFO_URL", ""),
+				},
+			},
+		},
+		Events: EventsConfig{
+			DispatchInterval:  time.Duration(getEnvInt("EVENTS_DISPATCH_INTERVAL_SECONDS", 5)) * time.Second,
+			DispatchBatchSize: getEnvInt("EVENTS_DISPATCH_BATCH_SIZE", 100),
+		},
+		RateLimit: RateLimitConfig{
+			DefaultLimit:   getEnvInt("RATE_LIMIT_DEFAULT_LIMIT", 100),
+			DefaultWindow:  time.Duration(getEnvInt("RATE_LIMIT_DEFAULT_WINDOW_SECONDS", 60)) * time.Second,
+			FailMode:       getEnv("RATE_LIMIT_FAIL_MODE", "open"),
+			TrustedProxies: parseCSV(getEnv("RATE_LIMIT_TRUSTED_PROXIES", "")),
+			Routes:         parseRouteLimitsJSON(getEnv("RATE_LIMIT_ROUTES_JSON", "")),
+		},
+	}
+}
+
+// parseCSV splits a comma-separated env var into a trimmed, non-empty
+// slice, e.g. for EXTERNAL_AUTH_PRIORITY="ldap,oidc". An empty or
+// all-whitespace raw value yields a nil slice.
+func parseCSV(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseRolesJSON decodes an RBAC_ROLES_JSON env var of the form
+// {"admin":["users:read","users:write"]}. An empty or malformed value
+// yields a nil map, leaving the built-in role defaults in place.
+func parseRolesJSON(raw string) map[string][]string {
+	if raw == "" {
+		return nil
+	}
+	var roles map[string][]string
+	if err := json.Unmarshal([]byte(raw), &roles); err != nil {
+		return nil
+	}
+	return roles
+}
+
+// parseRouteLimitsJSON decodes a RATE_LIMIT_ROUTES_JSON env var of the form
+// {"/api/v1/login":{"limit":10,"window_seconds":60,"burst":20}}. An empty
+// or malformed value yields a nil map, leaving any in-code SetRouteLimit
+// calls as the only overrides.
+func parseRouteLimitsJSON(raw string) map[string]RouteLimitOverride {
+	if raw == "" {
+		return nil
+	}
+	var routes map[string]RouteLimitOverride
+	if err := json.Unmarshal([]byte(raw), &routes); err != nil {
+		return nil
 	}
+	return routes
 }
 
 func (c *Config) DSN() string {
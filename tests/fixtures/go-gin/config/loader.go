@@ -0,0 +1,202 @@
+// config/loader.go - Layered file config with env expansion and secret URIs
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// LoadLayered builds a Config the same way Load() does, then overlays it
+// with up to three optional TOML files read from dir, each merging over the
+// previous and leaving any key it doesn't set untouched:
+//
+//	config.default.toml       - defaults shared by every environment
+//	config.{APP_ENV}.toml     - per-environment overrides
+//	config.local.toml         - untracked developer-machine overrides
+//
+// String values in every layer are expanded for ${VAR} / ${VAR:-default}
+// references before being parsed, and JWTSecret/RefreshSecret/Email.Password/
+// Database.Password are resolved if they carry a file:// or vault:// URI.
+func LoadLayered(dir string) (*Config, error) {
+	cfg := Load()
+
+	layers := []string{
+		"config.default.toml",
+		fmt.Sprintf("config.%s.toml", cfg.App.Environment),
+		"config.local.toml",
+	}
+	for _, name := range layers {
+		if err := mergeTOMLFile(filepath.Join(dir, name), cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := resolveSecrets(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func mergeTOMLFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if _, err := toml.Decode(expandEnv(string(data)), cfg); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// expandEnv replaces ${VAR} and ${VAR:-default} references with the named
+// environment variable, or the given default when it's unset.
+func expandEnv(s string) string {
+	return envRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := envRefPattern.FindStringSubmatch(match)
+		name, fallback := groups[1], groups[2]
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		return strings.TrimPrefix(fallback, ":-")
+	})
+}
+
+// resolveSecrets resolves a file:// or vault:// URI in each sensitive
+// field, so secrets can live outside the config files and plain env vars.
+func resolveSecrets(cfg *Config) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"Auth.JWTSecret", &cfg.Auth.JWTSecret},
+		{"Auth.RefreshSecret", &cfg.Auth.RefreshSecret},
+		{"Email.Password", &cfg.Email.Password},
+		{"Database.Password", &cfg.Database.Password},
+	}
+	for _, f := range fields {
+		resolved, err := resolveSecretValue(*f.value)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", f.name, err)
+		}
+		*f.value = resolved
+	}
+	return nil
+}
+
+func resolveSecretValue(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, "file://"):
+		data, err := os.ReadFile(strings.TrimPrefix(v, "file://"))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case strings.HasPrefix(v, "vault://"):
+		return resolveVaultSecret(v)
+	default:
+		return v, nil
+	}
+}
+
+// resolveVaultSecret resolves "vault://<mount>/<path>#<field>" against
+// Vault's KV v2 API, authenticating with VAULT_ADDR/VAULT_TOKEN from the
+// environment. It's intentionally minimal - just enough to pull a single
+// secret field at load time, not a general-purpose Vault client.
+func resolveVaultSecret(uri string) (string, error) {
+	ref := strings.TrimPrefix(uri, "vault://")
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok {
+		return "", fmt.Errorf("vault secret ref %q is missing a #field", uri)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	token := os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to resolve %q", uri)
+	}
+
+	mount, subPath, ok := strings.Cut(path, "/")
+	if !ok {
+		return "", fmt.Errorf("vault secret ref %q is missing a mount/path", uri)
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(addr, "/"), mount, subPath)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("vault request failed: %s: %s", resp.Status, string(body))
+	}
+
+	var parsed struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("decoding vault response: %w", err)
+	}
+
+	v, ok := parsed.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %q has no field %q", path, field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %q field %q is not a string", path, field)
+	}
+	return s, nil
+}
+
+// Validate rejects configs that are unsafe to run in production. It's a
+// no-op outside production so local/dev environments can keep the
+// convenience defaults.
+func (c *Config) Validate() error {
+	if !c.IsProduction() {
+		return nil
+	}
+
+	var problems []string
+	if c.Auth.JWTSecret == "change-me-in-production" {
+		problems = append(problems, "Auth.JWTSecret is still the insecure default")
+	}
+	if c.Auth.RefreshSecret == "change-me-in-production" {
+		problems = append(problems, "Auth.RefreshSecret is still the insecure default")
+	}
+	if c.Email.Password == "" {
+		problems = append(problems, "Email.Password is empty")
+	}
+	if c.Database.Password == "" {
+		problems = append(problems, "Database.Password is empty")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("insecure config for production: %s", strings.Join(problems, "; "))
+}
@@ -0,0 +1,83 @@
+// config/watch.go - Hot-reloading config store
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Store holds the currently-active Config behind an atomic pointer so a
+// reload can never be observed half-applied. Callers that need live values
+// (as opposed to the snapshot most services are constructed with today)
+// should read through Get() rather than holding a *Config directly.
+type Store struct {
+	ptr atomic.Pointer[Config]
+}
+
+func NewStore(initial *Config) *Store {
+	s := &Store{}
+	s.ptr.Store(initial)
+	return s
+}
+
+func (s *Store) Get() *Config {
+	return s.ptr.Load()
+}
+
+// Watch re-runs LoadLayered(dir) whenever one of its TOML layers changes on
+// disk, atomically swapping the Store's pointer and invoking onChange with
+// the new Config. It runs in the background until ctx is canceled; a failed
+// reload is logged and the previous config is kept in place.
+func (s *Store) Watch(ctx context.Context, dir string, onChange func(*Config)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("starting config watcher: %w", err)
+	}
+
+	env := s.Get().App.Environment
+	for _, name := range []string{"config.default.toml", fmt.Sprintf("config.%s.toml", env), "config.local.toml"} {
+		// Layers are optional; fsnotify can only watch files that exist, so
+		// a layer missing at this point isn't watched, and creating it
+		// later doesn't trigger a reload.
+		_ = watcher.Add(filepath.Join(dir, name))
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove) == 0 {
+					continue
+				}
+				cfg, err := LoadLayered(dir)
+				if err != nil {
+					log.Printf("config: reload failed, keeping previous config: %v", err)
+					continue
+				}
+				s.ptr.Store(cfg)
+				if onChange != nil {
+					onChange(cfg)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("config: watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
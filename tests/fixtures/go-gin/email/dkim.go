@@ -0,0 +1,82 @@
+// email/dkim.go - DKIM signing (RFC 6376, simple/simple canonicalization)
+
+package email
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DKIMSigner signs outgoing mail on behalf of Domain/Selector, using
+// simple/simple canonicalization (RFC 6376 section 3.4.1) - the body and
+// signed headers are taken byte-for-byte, which is the least likely
+// canonicalization to be mangled by a naive transport.
+type DKIMSigner struct {
+	Domain     string
+	Selector   string
+	PrivateKey *rsa.PrivateKey
+}
+
+// signedHeaders lists, in order, the headers included in the signature.
+// Order matters: it must match the h= tag and the order the headers are
+// fed to the hash.
+var signedHeaders = []string{"from", "to", "subject", "date"}
+
+// Sign computes a DKIM-Signature header value for a message built from
+// headers (lowercased names) and body, to be prepended to the outgoing
+// message. now is injected so signing is deterministic in tests.
+func (s *DKIMSigner) Sign(headers map[string]string, body string, now time.Time) (string, error) {
+	bodyHash := sha256.Sum256([]byte(canonicalizeBody(body)))
+
+	tagValue := fmt.Sprintf(
+		"v=1; a=rsa-sha256; c=simple/simple; d=%s; s=%s; h=%s; bh=%s; t=%d; b=",
+		s.Domain, s.Selector, strings.Join(signedHeaders, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]), now.Unix(),
+	)
+
+	var buf strings.Builder
+	for _, name := range signedHeaders {
+		if value, ok := headers[name]; ok {
+			buf.WriteString(canonicalHeaderName(name))
+			buf.WriteString(": ")
+			buf.WriteString(value)
+			buf.WriteString("\r\n")
+		}
+	}
+	buf.WriteString("DKIM-Signature: ")
+	buf.WriteString(tagValue)
+
+	digest := sha256.Sum256([]byte(buf.String()))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("dkim: signing header hash: %w", err)
+	}
+
+	return tagValue + base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// canonicalizeBody applies the "simple" body canonicalization: a trailing
+// run of empty lines is reduced to a single CRLF, and an empty body becomes
+// a lone CRLF.
+func canonicalizeBody(body string) string {
+	normalized := strings.ReplaceAll(body, "\r\n", "\n")
+	trimmed := strings.TrimRight(normalized, "\n")
+	return strings.ReplaceAll(trimmed, "\n", "\r\n") + "\r\n"
+}
+
+func canonicalHeaderName(name string) string {
+	parts := strings.Split(name, "-")
+	for i, p := range parts {
+		if len(p) == 0 {
+			continue
+		}
+		parts[i] = strings.ToUpper(p[:1]) + p[1:]
+	}
+	return strings.Join(parts, "-")
+}
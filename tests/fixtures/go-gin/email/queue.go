@@ -0,0 +1,159 @@
+// email/queue.go - Durable delivery queue backed by the email_jobs table
+
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"myapp/models"
+	"myapp/repository"
+)
+
+const defaultClaimBatchSize = 20
+
+// Queue decouples request handling from SMTP round trips: Enqueue persists
+// the message via EmailJobRepository and returns immediately, so a
+// restart or crash between accepting a request and actually delivering its
+// email doesn't silently drop it - see events.Outbox/events.Dispatcher for
+// the same durable-queue shape applied to domain events. Start runs a
+// single poller that claims due jobs and fans delivery out across a
+// bounded pool of goroutines, retrying a failed send with exponential
+// backoff before giving up and handing the message to OnDeadLetter.
+type Queue struct {
+	repo         *repository.EmailJobRepository
+	transport    Transport
+	workers      int
+	maxAttempts  int
+	baseBackoff  time.Duration
+	pollInterval time.Duration
+	OnDeadLetter func(msg *Message, err error)
+}
+
+// NewQueue does not start polling itself - call Start from a background
+// goroutine once repo is ready. workers/maxAttempts/baseBackoff/
+// pollInterval fall back to sane defaults (4, 5, 2s doubling, 1s) if left
+// at zero.
+func NewQueue(repo *repository.EmailJobRepository, transport Transport, workers, maxAttempts int, baseBackoff, pollInterval time.Duration) *Queue {
+	if workers <= 0 {
+		workers = 4
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+	if baseBackoff <= 0 {
+		baseBackoff = 2 * time.Second
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &Queue{
+		repo:         repo,
+		transport:    transport,
+		workers:      workers,
+		maxAttempts:  maxAttempts,
+		baseBackoff:  baseBackoff,
+		pollInterval: pollInterval,
+	}
+}
+
+// Enqueue persists msg so Start's poller picks it up, even across a
+// restart between now and then.
+func (q *Queue) Enqueue(msg *Message) {
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		log.Printf("email: marshaling headers for message to %s: %v", msg.To, err)
+		return
+	}
+
+	if err := q.repo.Create(context.Background(), &models.EmailJob{
+		ToAddress:     msg.To,
+		FromAddress:   msg.From,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		Headers:       string(headers),
+		NextAttemptAt: time.Now(),
+	}); err != nil {
+		log.Printf("email: persisting message to %s: %v", msg.To, err)
+	}
+}
+
+// Start polls for due jobs on pollInterval until ctx is done, delivering up
+// to workers of them at once. It runs one pass immediately on entry so a
+// freshly started queue doesn't sit idle for a full interval before its
+// first pass.
+func (q *Queue) Start(ctx context.Context) {
+	sem := make(chan struct{}, q.workers)
+
+	q.runOnce(ctx, sem)
+
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.runOnce(ctx, sem)
+		}
+	}
+}
+
+func (q *Queue) runOnce(ctx context.Context, sem chan struct{}) {
+	jobs, err := q.repo.ClaimBatch(ctx, time.Now(), defaultClaimBatchSize)
+	if err != nil {
+		log.Printf("email: claiming job batch failed: %v", err)
+		return
+	}
+
+	for _, j := range jobs {
+		sem <- struct{}{}
+		go func(j models.EmailJob) {
+			defer func() { <-sem }()
+			q.deliver(ctx, j)
+		}(j)
+	}
+}
+
+func (q *Queue) deliver(ctx context.Context, j models.EmailJob) {
+	var headers map[string]string
+	if j.Headers != "" {
+		if err := json.Unmarshal([]byte(j.Headers), &headers); err != nil {
+			log.Printf("email: decoding headers for job %s: %v", j.ID, err)
+		}
+	}
+	msg := &Message{To: j.ToAddress, From: j.FromAddress, Subject: j.Subject, Body: j.Body, Headers: headers}
+
+	err := q.transport.Send(ctx, msg)
+	if err == nil {
+		if markErr := q.repo.MarkSent(ctx, j.ID); markErr != nil {
+			log.Printf("email: marking job %s sent: %v", j.ID, markErr)
+		}
+		return
+	}
+
+	attempt := j.Attempts + 1
+	if attempt >= q.maxAttempts {
+		if markErr := q.repo.MarkDeadLettered(ctx, j.ID, err.Error()); markErr != nil {
+			log.Printf("email: dead-lettering job %s: %v", j.ID, markErr)
+		}
+		q.deadLetter(msg, err)
+		return
+	}
+
+	backoff := q.baseBackoff * time.Duration(1<<uint(attempt-1))
+	if markErr := q.repo.MarkRetry(ctx, j.ID, time.Now().Add(backoff), err.Error()); markErr != nil {
+		log.Printf("email: scheduling retry for job %s: %v", j.ID, markErr)
+	}
+}
+
+func (q *Queue) deadLetter(msg *Message, err error) {
+	if q.OnDeadLetter != nil {
+		q.OnDeadLetter(msg, err)
+		return
+	}
+	log.Printf("email: giving up on message to %s: %v", msg.To, err)
+}
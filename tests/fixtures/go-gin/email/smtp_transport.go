@@ -0,0 +1,117 @@
+// email/smtp_transport.go - SMTP transport with optional DKIM signing
+
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPTransport sends mail over SMTP, optionally DKIM-signing each message
+// first. Signer is nil when no DKIM key is configured, in which case
+// messages go out unsigned (fine for local/dev SMTP relays).
+type SMTPTransport struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	UseTLS   bool
+	Signer   *DKIMSigner
+}
+
+func (t *SMTPTransport) addr() string {
+	return fmt.Sprintf("%s:%d", t.Host, t.Port)
+}
+
+func (t *SMTPTransport) Send(ctx context.Context, msg *Message) error {
+	raw, err := t.build(msg)
+	if err != nil {
+		return fmt.Errorf("email: building message: %w", err)
+	}
+
+	var auth smtp.Auth
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+
+	if t.UseTLS {
+		return t.sendTLS(auth, msg, raw)
+	}
+	return smtp.SendMail(t.addr(), auth, msg.From, []string{msg.To}, raw)
+}
+
+// sendTLS is used for submission ports (465/587) that expect TLS from the
+// first byte rather than STARTTLS, which smtp.SendMail doesn't support.
+func (t *SMTPTransport) sendTLS(auth smtp.Auth, msg *Message, raw []byte) error {
+	conn, err := tls.Dial("tcp", t.addr(), &tls.Config{ServerName: t.Host})
+	if err != nil {
+		return fmt.Errorf("email: dialing smtp over tls: %w", err)
+	}
+	defer conn.Close()
+
+	client, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		return fmt.Errorf("email: smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("email: smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(msg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// build assembles the raw RFC 5322 message, prepending a DKIM-Signature
+// header when a signer is configured.
+func (t *SMTPTransport) build(msg *Message) ([]byte, error) {
+	headers := map[string]string{
+		"from":    msg.From,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"date":    time.Now().Format(time.RFC1123Z),
+	}
+
+	var sb strings.Builder
+
+	if t.Signer != nil {
+		signature, err := t.Signer.Sign(headers, msg.Body, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString("DKIM-Signature: " + signature + "\r\n")
+	}
+
+	sb.WriteString("From: " + headers["from"] + "\r\n")
+	sb.WriteString("To: " + headers["to"] + "\r\n")
+	sb.WriteString("Subject: " + headers["subject"] + "\r\n")
+	sb.WriteString("Date: " + headers["date"] + "\r\n")
+	for name, value := range msg.Headers {
+		sb.WriteString(name + ": " + value + "\r\n")
+	}
+	sb.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(msg.Body)
+
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,23 @@
+// email/transport.go - Pluggable email transport
+
+package email
+
+import "context"
+
+// Message is a transport-agnostic outbound email. Headers carries anything
+// beyond To/From/Subject (e.g. List-Unsubscribe) that a transport should
+// fold into the message it builds.
+type Message struct {
+	To      string
+	From    string
+	Subject string
+	Body    string
+	Headers map[string]string
+}
+
+// Transport delivers a single Message, e.g. over SMTP or a provider's HTTP
+// API. NewQueue accepts any implementation; service.NewEmailService always
+// builds an SMTPTransport.
+type Transport interface {
+	Send(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,58 @@
+// events/bus.go - In-process async publish/subscribe
+
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Handler receives one published Event. It runs in its own goroutine (see
+// Publish), so it must not assume anything about ordering relative to
+// other handlers or other events.
+type Handler func(ctx context.Context, event Event)
+
+// Bus fans an Event out to every Handler subscribed to its Type. Delivery
+// is asynchronous: Publish returns once each handler has been started, not
+// once it's finished - the right fit for the email/webhook/metrics
+// subscribers this bus exists for. See Outbox and Dispatcher for how an
+// event tied to a database mutation gets from there to here durably.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[string][]Handler)}
+}
+
+// Subscribe registers handler for every event whose Type() equals
+// eventType. Subscribing to "*" receives every event regardless of type.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[eventType] = append(b.subscribers[eventType], handler)
+}
+
+// Publish runs every handler subscribed to event.Type(), plus any "*"
+// subscriber, in its own goroutine, recovering a panicking handler so one
+// bad subscriber can't take down the publisher or any other subscriber.
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.subscribers[event.Type()])+len(b.subscribers["*"]))
+	handlers = append(handlers, b.subscribers[event.Type()]...)
+	handlers = append(handlers, b.subscribers["*"]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("events: subscriber panicked handling %s: %v", event.Type(), r)
+				}
+			}()
+			h(ctx, event)
+		}(handler)
+	}
+}
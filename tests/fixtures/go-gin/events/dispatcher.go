@@ -0,0 +1,64 @@
+// events/dispatcher.go - Polls the outbox and publishes events at least once
+
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"myapp/repository"
+)
+
+// Dispatcher polls OutboxRepository for events Outbox wrote inside a
+// caller's transaction and republishes each through Bus to the async
+// subscribers (email, webhooks, metrics) registered there. Delivery is
+// at-least-once: a crash between ClaimBatch and MarkProcessed replays that
+// event on the next poll, so subscribers must tolerate duplicates.
+type Dispatcher struct {
+	repo      *repository.OutboxRepository
+	bus       *Bus
+	interval  time.Duration
+	batchSize int
+}
+
+func NewDispatcher(repo *repository.OutboxRepository, bus *Bus, interval time.Duration, batchSize int) *Dispatcher {
+	return &Dispatcher{repo: repo, bus: bus, interval: interval, batchSize: batchSize}
+}
+
+// Start polls on interval until ctx is done, publishing and marking
+// processed every claimed batch. It runs one pass immediately on entry so
+// a freshly started dispatcher doesn't sit idle for a full interval before
+// its first pass.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.runOnce(ctx)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) runOnce(ctx context.Context) {
+	batch, err := d.repo.ClaimBatch(ctx, d.batchSize)
+	if err != nil {
+		log.Printf("events: claiming outbox batch failed: %v", err)
+		return
+	}
+
+	for _, row := range batch {
+		d.bus.Publish(ctx, RawEvent{EventType: row.EventType, Payload: []byte(row.Payload)})
+		if err := d.repo.MarkProcessed(ctx, row.ID); err != nil {
+			log.Printf("events: marking outbox event %s processed failed: %v", row.ID, err)
+			if incErr := d.repo.IncrementAttempts(ctx, row.ID); incErr != nil {
+				log.Printf("events: incrementing attempts for outbox event %s failed: %v", row.ID, incErr)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// events/event.go - Typed UserService domain events
+
+package events
+
+import "encoding/json"
+
+// Type names for every event UserService publishes. A Bus subscriber
+// matches on these, and the outbox stores them verbatim in its
+// event_type column.
+const (
+	TypeUserCreated       = "user.created"
+	TypeUserActivated     = "user.activated"
+	TypeUserSuspended     = "user.suspended"
+	TypePasswordChanged   = "user.password_changed"
+	TypeEmailChanged      = "user.email_changed"
+	TypeLoginSucceeded    = "user.login_succeeded"
+	TypeLoginFailed       = "user.login_failed"
+	TypeTOTPSecurityAlert = "user.totp_security_alert"
+)
+
+// Event is anything Bus can publish and Outbox can persist: Type
+// identifies which of the constants above it is, and the event itself is
+// JSON-marshaled verbatim as the outbox row's payload.
+type Event interface {
+	Type() string
+}
+
+type UserCreated struct {
+	UserID           string `json:"user_id"`
+	Email            string `json:"email"`
+	Username         string `json:"username"`
+	SendVerification bool   `json:"send_verification"`
+}
+
+func (UserCreated) Type() string { return TypeUserCreated }
+
+type UserActivated struct {
+	UserID string `json:"user_id"`
+}
+
+func (UserActivated) Type() string { return TypeUserActivated }
+
+type UserSuspended struct {
+	UserID       string `json:"user_id"`
+	Reason       string `json:"reason"`
+	DurationDays int    `json:"duration_days"`
+}
+
+func (UserSuspended) Type() string { return TypeUserSuspended }
+
+type PasswordChanged struct {
+	UserID string `json:"user_id"`
+}
+
+func (PasswordChanged) Type() string { return TypePasswordChanged }
+
+type EmailChanged struct {
+	UserID   string `json:"user_id"`
+	NewEmail string `json:"new_email"`
+}
+
+func (EmailChanged) Type() string { return TypeEmailChanged }
+
+type LoginSucceeded struct {
+	UserID string `json:"user_id"`
+}
+
+func (LoginSucceeded) Type() string { return TypeLoginSucceeded }
+
+type LoginFailed struct {
+	Identifier string `json:"identifier"`
+}
+
+func (LoginFailed) Type() string { return TypeLoginFailed }
+
+// TOTPSecurityAlert fires whenever a user's 2FA state changes in a way that
+// warrants telling them - enrollment confirmed, 2FA disabled, or a recovery
+// code (rather than the authenticator itself) used to authenticate - so
+// they notice if they didn't do it themselves. Action is one of "enabled",
+// "disabled", or "recovery_code_used".
+type TOTPSecurityAlert struct {
+	UserID string `json:"user_id"`
+	Action string `json:"action"`
+}
+
+func (TOTPSecurityAlert) Type() string { return TypeTOTPSecurityAlert }
+
+// RawEvent is what Dispatcher actually publishes: the outbox only stores
+// a type string and a JSON payload, not the original Go struct, so
+// subscribers decode Payload into whatever concrete type they expect for
+// EventType rather than type-asserting the original event.
+type RawEvent struct {
+	EventType string
+	Payload   []byte
+}
+
+func (e RawEvent) Type() string { return e.EventType }
+
+// Decode unmarshals Payload into v, the concrete event struct a subscriber
+// expects for this Type.
+func (e RawEvent) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
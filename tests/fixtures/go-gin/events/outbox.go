@@ -0,0 +1,39 @@
+// events/outbox.go - Transactional write-side of the event outbox
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+	"myapp/repository"
+)
+
+// Outbox persists an Event as part of the caller's database transaction,
+// so the event is never lost to a crash between committing a state change
+// and publishing it. Dispatcher polls for what Outbox wrote and publishes
+// it at least once via a Bus.
+type Outbox struct {
+	repo *repository.OutboxRepository
+}
+
+func NewOutbox(repo *repository.OutboxRepository) *Outbox {
+	return &Outbox{repo: repo}
+}
+
+// WriteTx serializes event and inserts it into the outbox table using tx,
+// so it commits (or rolls back) atomically with whatever state change
+// caused it.
+func (o *Outbox) WriteTx(ctx context.Context, tx *gorm.DB, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return o.repo.WithTx(tx).Create(ctx, &models.OutboxEvent{
+		EventType: event.Type(),
+		Payload:   string(payload),
+	})
+}
@@ -0,0 +1,53 @@
+// handler/audit_handler.go - Admin audit log inspection
+
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/repository"
+)
+
+type AuditHandler struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditHandler(auditRepo *repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{auditRepo: auditRepo}
+}
+
+// ListAuditLogs returns a keyset-paginated, newest-first view of the audit
+// trail, optionally filtered down to one actor, target, or action.
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	filter := repository.AuditLogFilter{
+		Action:  c.Query("action"),
+		SortDir: repository.SortDir(c.DefaultQuery("sort_dir", string(repository.SortDesc))),
+	}
+
+	if actorID, err := uuid.Parse(c.Query("actor_id")); err == nil {
+		filter.ActorID = &actorID
+	}
+	if targetID, err := uuid.Parse(c.Query("target_id")); err == nil {
+		filter.TargetID = &targetID
+	}
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		filter.Cursor = &cursor
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.auditRepo.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":     result.Entries,
+		"total":       result.Total,
+		"next_cursor": result.NextCursor,
+	})
+}
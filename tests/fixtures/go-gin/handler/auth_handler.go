@@ -0,0 +1,239 @@
+// handler/auth_handler.go - Password login and TOTP 2FA routes
+
+package handler
+
+import (
+	"encoding/base64"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/middleware"
+	"myapp/service"
+)
+
+type AuthHandler struct {
+	userService    *service.UserService
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func NewAuthHandler(userService *service.UserService, authMiddleware *middleware.AuthMiddleware) *AuthHandler {
+	return &AuthHandler{
+		userService:    userService,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// RegisterRoutes registers the public login routes
+func (h *AuthHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/login", h.Login)
+	r.POST("/login/2fa", h.Login2FA)
+}
+
+// RegisterProtectedRoutes registers the 2FA enrollment/management routes,
+// which require a fully-authenticated (non-pending) access token.
+func (h *AuthHandler) RegisterProtectedRoutes(r *gin.RouterGroup) {
+	twoFA := r.Group("/2fa")
+	{
+		twoFA.POST("/enroll", h.Enroll2FA)
+		twoFA.POST("/confirm", h.Confirm2FA)
+		twoFA.POST("/disable", h.Disable2FA)
+		twoFA.POST("/recovery-codes/regenerate", h.RegenerateRecoveryCodes)
+	}
+}
+
+// Login verifies the password and, if the account has 2FA enabled, returns
+// a short-lived pending token instead of a real session - the caller must
+// then hit Login2FA with a TOTP or recovery code to finish.
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.Authenticate(c.Request.Context(), input.Email, input.Password)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+		return
+	}
+
+	if h.userService.HasTOTPEnabled(c.Request.Context(), user.ID) {
+		pendingToken, err := h.authMiddleware.GeneratePendingToken(user.ID, user.Email, string(user.Role))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start 2fa challenge"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"requires_2fa":  true,
+			"pending_token": pendingToken,
+		})
+		return
+	}
+
+	accessToken, refreshToken, err := h.authMiddleware.GenerateTokenPair(c.Request.Context(), user.ID, user.Email, string(user.Role), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	if err := h.userService.UpdateLastLogin(c.Request.Context(), user.ID, c.ClientIP()); err != nil {
+		log.Printf("auth: failed to record last login for %s: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Login2FA exchanges a pending token plus a TOTP or recovery code for a
+// real access/refresh token pair. Login defers LastLoginAt to this handler
+// for any account with confirmed 2FA, so a password alone never marks the
+// account as logged in - only clearing the second factor does.
+func (h *AuthHandler) Login2FA(c *gin.Context) {
+	var input struct {
+		PendingToken string `json:"pending_token" binding:"required"`
+		Code         string `json:"code" binding:"required"`
+		RecoveryCode bool   `json:"recovery_code"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := h.authMiddleware.ParsePendingToken(input.PendingToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired pending token"})
+		return
+	}
+
+	var ok bool
+	if input.RecoveryCode {
+		ok, err = h.userService.ConsumeRecoveryCode(c.Request.Context(), claims.UserID, input.Code)
+	} else {
+		ok, err = h.userService.VerifyTOTP(c.Request.Context(), claims.UserID, input.Code)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid 2fa code"})
+		return
+	}
+
+	accessToken, refreshToken, err := h.authMiddleware.GenerateTokenPair(c.Request.Context(), claims.UserID, claims.Email, claims.Role, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	if err := h.userService.UpdateLastLogin(c.Request.Context(), claims.UserID, c.ClientIP()); err != nil {
+		log.Printf("auth: failed to record last login for %s: %v", claims.UserID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+	})
+}
+
+// Enroll2FA generates a new TOTP secret for the authenticated user and
+// returns the otpauth URI plus a base64-encoded QR code PNG to scan.
+func (h *AuthHandler) Enroll2FA(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	email := c.GetString("user_email")
+
+	otpauthURL, qrPNG, err := h.userService.EnrollTOTP(c.Request.Context(), userID, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"otpauth_url": otpauthURL,
+		"qr_code_png": base64.StdEncoding.EncodeToString(qrPNG),
+	})
+}
+
+// Confirm2FA activates 2FA after the user proves possession of the
+// enrolled secret, returning one-time recovery codes.
+func (h *AuthHandler) Confirm2FA(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.userService.ConfirmTOTP(c.Request.Context(), userID, input.Code)
+	if err != nil {
+		if err == service.ErrInvalidTOTPCode {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// RegenerateRecoveryCodes invalidates every unused recovery code and issues
+// a fresh set, for a user who has burned through most of their originals.
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.userService.RegenerateRecoveryCodes(c.Request.Context(), userID, input.Code)
+	if err != nil {
+		if err == service.ErrInvalidTOTPCode {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// Disable2FA turns 2FA off after re-verifying the current code.
+func (h *AuthHandler) Disable2FA(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.DisableTOTP(c.Request.Context(), userID, input.Code); err != nil {
+		if err == service.ErrInvalidTOTPCode {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2fa disabled"})
+}
@@ -0,0 +1,94 @@
+// handler/auth_source_handler.go - External auth source (LDAP/OIDC ROPC) linking
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/auth"
+	"myapp/service"
+)
+
+// AuthSourceHandler exposes account linking for auth.LoginProvider sources
+// (LDAP, OIDC ROPC) - providers whose login is a direct credential check
+// rather than a browser redirect, so they don't fit OAuthHandler's
+// state-cookie flow. Login itself needs no dedicated route: UserService.
+// Authenticate already tries these sources from the regular login endpoint.
+type AuthSourceHandler struct {
+	providers   []auth.LoginProvider
+	userService *service.UserService
+}
+
+func NewAuthSourceHandler(providers []auth.LoginProvider, userService *service.UserService) *AuthSourceHandler {
+	return &AuthSourceHandler{providers: providers, userService: userService}
+}
+
+// RegisterProtectedRoutes registers the authenticated account-linking routes.
+func (h *AuthSourceHandler) RegisterProtectedRoutes(r *gin.RouterGroup) {
+	sources := r.Group("/auth-sources")
+	{
+		sources.POST("/:provider/link", h.Link)
+		sources.DELETE("/:provider", h.Unlink)
+	}
+}
+
+type linkAuthSourceRequest struct {
+	Identifier string `json:"identifier" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+}
+
+func (h *AuthSourceHandler) provider(name string) (auth.LoginProvider, bool) {
+	for _, p := range h.providers {
+		if p.Name() == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Link verifies identifier/password directly against the named external
+// source and, on success, attaches the resulting identity to the
+// authenticated user - the same "prove it, then attach it" shape as
+// OAuthHandler.finishLink, just without a redirect round trip in between.
+func (h *AuthSourceHandler) Link(c *gin.Context) {
+	provider, ok := h.provider(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown auth source"})
+		return
+	}
+
+	var req linkAuthSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	info, err := provider.Login(c.Request.Context(), req.Identifier, req.Password)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.userService.LinkAuthSource(c.Request.Context(), userID, info); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"linked": true, "provider": info.Provider})
+}
+
+// Unlink removes the link between the authenticated user and the named
+// external source, refusing if it would leave the account with no way to
+// sign in (see UserService.UnlinkIdentity).
+func (h *AuthSourceHandler) Unlink(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	if err := h.userService.UnlinkAuthSource(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unlinked": true})
+}
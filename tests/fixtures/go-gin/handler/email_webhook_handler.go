@@ -0,0 +1,41 @@
+// handler/email_webhook_handler.go - Provider bounce/complaint callbacks
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/service"
+)
+
+type EmailWebhookHandler struct {
+	emailService *service.EmailService
+}
+
+func NewEmailWebhookHandler(emailService *service.EmailService) *EmailWebhookHandler {
+	return &EmailWebhookHandler{emailService: emailService}
+}
+
+// HandleEvent accepts a provider-agnostic bounce/complaint notification.
+// Real providers (SES, SendGrid, Postmark, ...) each use their own payload
+// shape; translating theirs into this one is a thin adapter at the edge of
+// the webhook route rather than something this handler needs to know about.
+func (h *EmailWebhookHandler) HandleEvent(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+		Type  string `json:"type" binding:"required,oneof=bounce complaint"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.emailService.Suppress(c.Request.Context(), input.Email, input.Type); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recorded"})
+}
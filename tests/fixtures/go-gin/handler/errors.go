@@ -0,0 +1,125 @@
+// handler/errors.go - Structured error envelope for request validation and
+// service-layer failures, so clients get stable codes instead of raw
+// validator/Go error strings.
+
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// APIError is the JSON body RespondError writes directly (validation and
+// malformed-body failures). Service-layer sentinel errors instead go
+// through c.Error and middleware.ErrorResponder, which renders the same
+// shape from a service.Error.
+type APIError struct {
+	Code      string           `json:"code"`
+	Message   string           `json:"message"`
+	Details   []APIErrorDetail `json:"details,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+}
+
+// APIErrorDetail describes why a single field failed validation. Code is a
+// stable, per-tag identifier (e.g. "min_length"); Params holds the tag's
+// argument, if any (e.g. {"min": "8"}).
+type APIErrorDetail struct {
+	Field   string            `json:"field"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
+// validationTagCodes maps go-playground/validator tags to the stable codes
+// documented for API clients. A tag not listed here falls back to the tag
+// name itself, so an unrecognized tag still produces a usable code.
+var validationTagCodes = map[string]string{
+	"required": "required",
+	"email":    "invalid_email",
+	"min":      "min_length",
+	"max":      "max_length",
+	"gte":      "min_value",
+	"lte":      "max_value",
+	"oneof":    "invalid_choice",
+}
+
+// RespondError writes the appropriate response for err and should be the
+// only way handlers report a failure:
+//   - validator.ValidationErrors (from ShouldBindJSON) becomes a 400 with
+//     field-level Details clients can localize without parsing Message.
+//   - a malformed request body becomes a plain 400.
+//   - anything else, including a *service.Error, is pushed via c.Error so
+//     middleware.ErrorResponder renders it - that's the single place a
+//     service.Error's code and status are mapped to JSON.
+func RespondError(c *gin.Context, err error) {
+	var verr validator.ValidationErrors
+	if errors.As(err, &verr) {
+		c.JSON(http.StatusBadRequest, APIError{
+			Code:      "validation_failed",
+			Message:   "validation failed",
+			Details:   translateValidationErrors(verr),
+			RequestID: c.GetString("request_id"),
+		})
+		return
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		c.JSON(http.StatusBadRequest, APIError{
+			Code:      "malformed_body",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+		})
+		return
+	}
+
+	c.Error(err)
+}
+
+// translateValidationErrors turns validator.ValidationErrors from
+// ShouldBindJSON into the field-level shape clients can render directly,
+// e.g. a `min=8` failure on NewPassword becomes
+// {field: "new_password", code: "min_length", params: {"min": "8"}}.
+func translateValidationErrors(verr validator.ValidationErrors) []APIErrorDetail {
+	details := make([]APIErrorDetail, 0, len(verr))
+	for _, fe := range verr {
+		code, ok := validationTagCodes[fe.Tag()]
+		if !ok {
+			code = fe.Tag()
+		}
+
+		detail := APIErrorDetail{
+			Field:   jsonFieldName(fe.Field()),
+			Code:    code,
+			Message: fe.Error(),
+		}
+		if param := fe.Param(); param != "" {
+			detail.Params = map[string]string{fe.Tag(): param}
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// jsonFieldName lowercases and snake-cases a Go struct field name to match
+// the `json` tag convention used across this codebase (NewPassword ->
+// new_password), since validator reports the Go field name, not the tag.
+func jsonFieldName(field string) string {
+	var out []byte
+	for i, r := range field {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			out = append(out, byte(r-'A'+'a'))
+		} else {
+			out = append(out, byte(r))
+		}
+	}
+	return string(out)
+}
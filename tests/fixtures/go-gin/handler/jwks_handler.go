@@ -0,0 +1,25 @@
+// handler/jwks_handler.go - Public signing key discovery
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/middleware"
+)
+
+type JWKSHandler struct {
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func NewJWKSHandler(authMiddleware *middleware.AuthMiddleware) *JWKSHandler {
+	return &JWKSHandler{authMiddleware: authMiddleware}
+}
+
+// GetJWKS serves every currently-valid access token signing key, so other
+// services can verify our tokens without calling back into this one.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authMiddleware.JWKS())
+}
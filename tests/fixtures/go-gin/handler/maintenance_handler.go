@@ -0,0 +1,51 @@
+// handler/maintenance_handler.go - Admin maintenance job inspection/trigger
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/maintenance"
+)
+
+type MaintenanceHandler struct {
+	scheduler *maintenance.Scheduler
+}
+
+func NewMaintenanceHandler(scheduler *maintenance.Scheduler) *MaintenanceHandler {
+	return &MaintenanceHandler{scheduler: scheduler}
+}
+
+// ListJobs returns the name of every registered maintenance job, so an
+// operator knows what's valid to pass to TriggerJob.
+func (h *MaintenanceHandler) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": h.scheduler.Jobs()})
+}
+
+// TriggerJob runs one named job immediately, outside its regular interval,
+// and reports its outcome synchronously.
+func (h *MaintenanceHandler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+
+	result, err := h.scheduler.Trigger(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"job":           result.Job,
+		"rows_affected": result.RowsAffected,
+		"duration_ms":   result.Duration.Milliseconds(),
+		"skipped":       result.Skipped,
+	}
+	if result.Err != nil {
+		response["error"] = result.Err.Error()
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
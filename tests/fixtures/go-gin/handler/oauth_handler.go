@@ -0,0 +1,219 @@
+// handler/oauth_handler.go - SSO login/callback routes
+
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/auth"
+	"myapp/config"
+	"myapp/middleware"
+	"myapp/service"
+)
+
+const oauthStateCookie = "oauth_state"
+const oauthStateTTL = 10 * time.Minute
+
+type OAuthHandler struct {
+	providers      map[string]auth.OAuthProvider
+	states         auth.StateStore
+	userService    *service.UserService
+	authMiddleware *middleware.AuthMiddleware
+	frontendURL    string
+}
+
+func NewOAuthHandler(cfg *config.Config, userService *service.UserService, authMiddleware *middleware.AuthMiddleware) *OAuthHandler {
+	return &OAuthHandler{
+		providers:      auth.NewProviders(cfg.OAuth),
+		states:         auth.NewMemoryStateStore(),
+		userService:    userService,
+		authMiddleware: authMiddleware,
+		frontendURL:    cfg.OAuth.FrontendURL,
+	}
+}
+
+// RegisterRoutes registers the public OAuth login/callback routes
+func (h *OAuthHandler) RegisterRoutes(r *gin.RouterGroup) {
+	oauth := r.Group("/oauth")
+	{
+		oauth.GET("/:provider/login", h.Login)
+		oauth.GET("/:provider/callback", h.Callback)
+	}
+}
+
+// RegisterProtectedRoutes registers the authenticated account-linking routes:
+// starting a link round trip, listing linked identities, and unlinking one.
+// The callback itself stays on the public group (RegisterRoutes) since the
+// provider redirects back without the caller's session cookie - the link
+// target is instead recovered from the state via auth.StateStore.
+func (h *OAuthHandler) RegisterProtectedRoutes(r *gin.RouterGroup) {
+	oauth := r.Group("/oauth")
+	{
+		oauth.GET("/:provider/link", h.StartLink)
+		oauth.GET("/identities", h.ListIdentities)
+		oauth.DELETE("/:provider/identities", h.Unlink)
+	}
+}
+
+func (h *OAuthHandler) provider(c *gin.Context) (auth.OAuthProvider, bool) {
+	p, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown oauth provider"})
+	}
+	return p, ok
+}
+
+func (h *OAuthHandler) Login(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	state, codeChallenge, err := h.states.Generate(oauthStateTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start oauth flow"})
+		return
+	}
+
+	c.SetCookie(oauthStateCookie, state, int(oauthStateTTL.Seconds()), "/", "", false, true)
+	c.Redirect(http.StatusFound, provider.AuthorizeURL(state, codeChallenge))
+}
+
+// StartLink begins an account-linking round trip for the authenticated
+// user: unlike Login, the state is bound server-side to userID via
+// GenerateForLink, so Callback can attach the resulting identity to this
+// user's account rather than logging in as whoever it belongs to.
+func (h *OAuthHandler) StartLink(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	state, codeChallenge, err := h.states.GenerateForLink(oauthStateTTL, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start oauth flow"})
+		return
+	}
+
+	c.SetCookie(oauthStateCookie, state, int(oauthStateTTL.Seconds()), "/", "", false, true)
+	c.Redirect(http.StatusFound, provider.AuthorizeURL(state, codeChallenge))
+}
+
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+
+	if errMsg := c.Query("error"); errMsg != "" {
+		h.redirectWithError(c, errMsg)
+		return
+	}
+
+	cookieState, err := c.Cookie(oauthStateCookie)
+	if err != nil || cookieState == "" || cookieState != c.Query("state") {
+		h.redirectWithError(c, "invalid oauth state")
+		return
+	}
+	linkUserID, codeVerifier, ok := h.states.Consume(cookieState)
+	if !ok {
+		h.redirectWithError(c, "invalid oauth state")
+		return
+	}
+
+	token, err := provider.Exchange(c.Request.Context(), c.Query("code"), codeVerifier)
+	if err != nil {
+		h.redirectWithError(c, "token exchange failed")
+		return
+	}
+
+	if err := provider.VerifyIDToken(c.Request.Context(), token); err != nil {
+		h.redirectWithError(c, "id token verification failed")
+		return
+	}
+
+	info, err := provider.FetchUserInfo(c.Request.Context(), token)
+	if err != nil {
+		h.redirectWithError(c, "failed to fetch user info")
+		return
+	}
+
+	if linkUserID != nil {
+		h.finishLink(c, *linkUserID, info)
+		return
+	}
+
+	user, err := h.userService.LoginWithOAuth(c.Request.Context(), info)
+	if err != nil {
+		h.redirectWithError(c, "failed to complete login")
+		return
+	}
+
+	accessToken, refreshToken, err := h.authMiddleware.GenerateTokenPair(c.Request.Context(), user.ID, user.Email, string(user.Role), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		h.redirectWithError(c, "failed to issue tokens")
+		return
+	}
+
+	if h.frontendURL == "" {
+		c.JSON(http.StatusOK, gin.H{"access_token": accessToken, "refresh_token": refreshToken})
+		return
+	}
+
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s?access_token=%s&refresh_token=%s", h.frontendURL, accessToken, refreshToken))
+}
+
+// finishLink attaches the fetched identity to userID and redirects back to
+// the frontend (or renders JSON, for clients that skip the redirect step).
+func (h *OAuthHandler) finishLink(c *gin.Context, userID uuid.UUID, info auth.UserInfo) {
+	if err := h.userService.LinkIdentity(c.Request.Context(), userID, info); err != nil {
+		h.redirectWithError(c, "failed to link account")
+		return
+	}
+
+	if h.frontendURL == "" {
+		c.JSON(http.StatusOK, gin.H{"linked": true, "provider": info.Provider})
+		return
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s?linked=%s", h.frontendURL, info.Provider))
+}
+
+// ListIdentities lists the SSO identities linked to the authenticated user.
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	identities, err := h.userService.ListLinkedIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// Unlink removes the link between the authenticated user and the given
+// provider, refusing if it would leave the account with no way to sign in.
+func (h *OAuthHandler) Unlink(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	if err := h.userService.UnlinkIdentity(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OAuthHandler) redirectWithError(c *gin.Context, message string) {
+	if h.frontendURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s?error=%s", h.frontendURL, message))
+}
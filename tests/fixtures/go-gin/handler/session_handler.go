@@ -0,0 +1,37 @@
+// handler/session_handler.go - Session inspection for admins
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/repository"
+)
+
+type SessionHandler struct {
+	sessionRepo *repository.SessionRepository
+}
+
+func NewSessionHandler(sessionRepo *repository.SessionRepository) *SessionHandler {
+	return &SessionHandler{sessionRepo: sessionRepo}
+}
+
+// ListUserSessions returns every active refresh session for a given user.
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListActiveForUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
@@ -5,21 +5,25 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"myapp/middleware"
 	"myapp/models"
 	"myapp/repository"
+	"myapp/role"
 	"myapp/service"
 )
 
 type UserHandler struct {
-	userService *service.UserService
+	userService    *service.UserService
+	authMiddleware *middleware.AuthMiddleware
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, authMiddleware *middleware.AuthMiddleware) *UserHandler {
+	return &UserHandler{userService: userService, authMiddleware: authMiddleware}
 }
 
 // RegisterRoutes registers user routes
@@ -29,14 +33,21 @@ func (h *UserHandler) RegisterRoutes(r *gin.RouterGroup) {
 		users.GET("", h.ListUsers)
 		users.GET("/:id", h.GetUser)
 		users.POST("", h.CreateUser)
-		users.PUT("/:id", h.UpdateUser)
+		users.PUT("/:id", h.authMiddleware.RequirePermission(
+			[]role.Permission{role.PermUsersWrite},
+			middleware.SelfParamChecker("id"),
+		), h.UpdateUser)
 		users.DELETE("/:id", h.DeleteUser)
 		users.GET("/me", h.GetCurrentUser)
 		users.PATCH("/me/profile", h.UpdateProfile)
 		users.PATCH("/me/preferences", h.UpdatePreferences)
 		users.POST("/me/change-password", h.ChangePassword)
-		users.POST("/:id/activate", h.ActivateUser)
-		users.POST("/:id/suspend", h.SuspendUser)
+		users.POST("/:id/activate", h.authMiddleware.RequirePermission(
+			[]role.Permission{role.PermUsersWrite},
+		), h.ActivateUser)
+		users.POST("/:id/suspend", h.authMiddleware.RequirePermission(
+			[]role.Permission{role.PermUsersWrite},
+		), h.SuspendUser)
 		users.GET("/stats", h.GetStats)
 		users.GET("/search", h.SearchUsers)
 	}
@@ -49,23 +60,70 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	opts := repository.ListOptions{
 		Page:     page,
 		PageSize: pageSize,
-		Role:     c.Query("role"),
 		Status:   c.Query("status"),
 		Search:   c.Query("search"),
 	}
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), opts)
+	// Multiple ?role= values select the admin IN-filter; a single value
+	// keeps using the simple equality filter.
+	if roles := c.QueryArray("role"); len(roles) > 1 {
+		opts.Roles = roles
+	} else {
+		opts.Role = c.Query("role")
+	}
+
+	if emailVerified, ok := c.GetQuery("email_verified"); ok {
+		v := emailVerified == "true"
+		opts.EmailVerified = &v
+	}
+	if createdAfter, ok := c.GetQuery("created_after"); ok {
+		if t, err := time.Parse(time.RFC3339, createdAfter); err == nil {
+			opts.CreatedAfter = &t
+		}
+	}
+	if createdBefore, ok := c.GetQuery("created_before"); ok {
+		if t, err := time.Parse(time.RFC3339, createdBefore); err == nil {
+			opts.CreatedBefore = &t
+		}
+	}
+	if lastLoginAfter, ok := c.GetQuery("last_login_after"); ok {
+		if t, err := time.Parse(time.RFC3339, lastLoginAfter); err == nil {
+			opts.LastLoginAfter = &t
+		}
+	}
+
+	// Keyset mode: presence of ?cursor takes priority over ?page.
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		opts.Cursor = &cursor
+		opts.Limit = limit
+		opts.SortBy = repository.SortField(c.DefaultQuery("sort_by", string(repository.SortByCreatedAt)))
+		opts.SortDir = repository.SortDir(c.DefaultQuery("sort_dir", string(repository.SortDesc)))
+	}
+
+	result, err := h.userService.ListUsers(c.Request.Context(), opts)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
+		return
+	}
+
+	if opts.Cursor != nil {
+		// No "total": cursor mode skips COUNT(*) so it scales to large
+		// tables - see UserRepository.List.
+		c.JSON(http.StatusOK, gin.H{
+			"users":       result.Users,
+			"next_cursor": result.NextCursor,
+			"prev_cursor": result.PrevCursor,
+		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"users":       users,
-		"total":       total,
+		"users":       result.Users,
+		"total":       result.Total,
 		"page":        page,
 		"page_size":   pageSize,
-		"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+		"total_pages": (result.Total + int64(pageSize) - 1) / int64(pageSize),
 	})
 }
 
@@ -78,43 +136,49 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 	user, err := h.userService.GetUser(c.Request.Context(), id)
 	if err != nil {
-		if err == service.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
-// Q: How should we handle input validation and return structured error responses?
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var input service.CreateUserInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	input.SendVerification = true
 
-	user, err := h.userService.CreateUser(c.Request.Context(), input)
+	user, err := h.userService.CreateUser(c.Request.Context(), input, h.auditContext(c))
 	if err != nil {
-		switch err {
-		case service.ErrEmailExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "email already registered"})
-		case service.ErrUsernameExists:
-			c.JSON(http.StatusConflict, gin.H{"error": "username already taken"})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, user)
 }
 
+// auditContext builds an AuditContext from the request, for handlers whose
+// service calls write an audit log entry alongside their mutation. ActorID
+// is nil when the route has no authenticated caller (e.g. self-registration).
+func (h *UserHandler) auditContext(c *gin.Context) service.AuditContext {
+	audit := service.AuditContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		id := userID.(uuid.UUID)
+		audit.ActorID = &id
+	}
+	if userRole := c.GetString("user_role"); userRole != "" {
+		audit.ActorRole = models.UserRole(userRole)
+	}
+	return audit
+}
+
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -122,19 +186,15 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var updates map[string]interface{}
-	if err := c.ShouldBindJSON(&updates); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input service.UpdateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		RespondError(c, err)
 		return
 	}
 
-	user, err := h.userService.UpdateUser(c.Request.Context(), id, updates)
+	user, err := h.userService.UpdateUser(c.Request.Context(), id, input, h.auditContext(c))
 	if err != nil {
-		if err == service.ErrUserNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -148,8 +208,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.userService.DeleteUser(c.Request.Context(), id, h.auditContext(c)); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -162,30 +222,35 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 
 	user, err := h.userService.GetUser(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, user)
 }
 
+// UpdateProfile binds straight into service.UpdateUserInput: the body is
+// expected to only carry profile fields, but since UpdateUser itself
+// rejects admin-only fields unless the caller holds PermUsersWrite, a
+// self-editing user who sneaks a "role" key into this request is rejected
+// exactly the same way as on the admin PUT route.
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	id := userID.(uuid.UUID)
 
-	var input service.UpdateProfileInput
+	var input service.UpdateUserInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	profile, err := h.userService.UpdateProfile(c.Request.Context(), id, input)
+	user, err := h.userService.UpdateUser(c.Request.Context(), id, input, h.auditContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, profile)
+	c.JSON(http.StatusOK, user.Profile)
 }
 
 func (h *UserHandler) UpdatePreferences(c *gin.Context) {
@@ -208,16 +273,12 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
 	if err := h.userService.ChangePassword(c.Request.Context(), id, input.CurrentPassword, input.NewPassword); err != nil {
-		if err == service.ErrInvalidPassword {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "incorrect current password"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -231,9 +292,9 @@ func (h *UserHandler) ActivateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.ActivateUser(c.Request.Context(), id)
+	user, err := h.userService.ActivateUser(c.Request.Context(), id, h.auditContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -253,9 +314,9 @@ func (h *UserHandler) SuspendUser(c *gin.Context) {
 	}
 	c.ShouldBindJSON(&input)
 
-	user, err := h.userService.SuspendUser(c.Request.Context(), id, input.Reason, input.DurationDays)
+	user, err := h.userService.SuspendUser(c.Request.Context(), id, input.Reason, input.DurationDays, h.auditContext(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -265,7 +326,7 @@ func (h *UserHandler) SuspendUser(c *gin.Context) {
 func (h *UserHandler) GetStats(c *gin.Context) {
 	stats, err := h.userService.GetStats(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
@@ -278,7 +339,7 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 
 	users, err := h.userService.SearchUsers(c.Request.Context(), query, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		RespondError(c, err)
 		return
 	}
 
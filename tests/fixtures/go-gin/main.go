@@ -3,25 +3,53 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"myapp/auth"
 	"myapp/config"
+	"myapp/events"
 	"myapp/handler"
+	"myapp/maintenance"
 	"myapp/middleware"
 	"myapp/models"
 	"myapp/repository"
+	"myapp/role"
 	"myapp/service"
+	"myapp/utils"
 )
 
 func main() {
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.LoadLayered(".")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	role.LoadConfig(cfg.RBAC.Roles)
+
+	// Watch the config files for changes so ops-owned settings (RBAC roles)
+	// can be tuned without a restart. Services already
+	// constructed below hold their own *config.Config snapshot from cfg, so
+	// reloads only take effect where they're re-read through configStore.
+	configStore := config.NewStore(cfg)
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+	if err := configStore.Watch(watchCtx, ".", func(reloaded *config.Config) {
+		role.LoadConfig(reloaded.RBAC.Roles)
+		log.Println("config: reloaded from disk")
+	}); err != nil {
+		log.Printf("config: hot reload disabled: %v", err)
+	}
 
 	// Set Gin mode
 	if cfg.IsProduction() {
@@ -40,53 +68,187 @@ func main() {
 		&models.UserProfile{},
 		&models.UserAddress{},
 		&models.UserPreferences{},
+		&models.UserIdentity{},
+		&models.RefreshSession{},
+		&models.UserTOTP{},
+		&models.TOTPRecoveryCode{},
+		&models.AuditLog{},
+		&models.EmailSuppression{},
+		&models.OutboxEvent{},
+		&models.SigningKey{},
+		&models.EmailJob{},
 	); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
+	userRepo := repository.NewUserRepository(db, cfg.Auth.JWTSecret)
+	identityRepo := repository.NewIdentityRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	totpRepo := repository.NewTOTPRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	emailSuppressionRepo := repository.NewEmailSuppressionRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	emailJobRepo := repository.NewEmailJobRepository(db)
+	tx := repository.NewTransactor(db)
 
 	// Initialize services
-	emailService := service.NewEmailService(cfg)
-	userService := service.NewUserService(userRepo, emailService)
+	emailService := service.NewEmailService(cfg, emailSuppressionRepo, emailJobRepo)
 
-	// Initialize handlers
-	userHandler := handler.NewUserHandler(userService)
+	// Event bus: UserService durably records state-change events via
+	// eventOutbox inside its own transactions; eventDispatcher polls them
+	// off the outbox table and republishes each to eventBus's async
+	// subscribers (currently just email; webhooks/metrics can subscribe
+	// the same way without UserService ever knowing about them).
+	eventBus := events.NewBus()
+	eventOutbox := events.NewOutbox(outboxRepo)
+	eventDispatcher := events.NewDispatcher(outboxRepo, eventBus, cfg.Events.DispatchInterval, cfg.Events.DispatchBatchSize)
+	service.NewEmailEventSubscriber(userRepo, emailService).Register(eventBus)
+
+	eventsCtx, cancelEvents := context.WithCancel(context.Background())
+	defer cancelEvents()
+	go eventDispatcher.Start(eventsCtx)
+
+	emailQueueCtx, cancelEmailQueue := context.WithCancel(context.Background())
+	defer cancelEmailQueue()
+	go emailService.Start(emailQueueCtx)
+
+	passwordPolicy := utils.NewPasswordPolicy(utils.PasswordPolicyConfig{
+		MinLength:          cfg.Auth.PasswordMinLength,
+		BreachCheckEnabled: cfg.Auth.PasswordBreachCheckEnabled,
+		BreachCheckTimeout: cfg.Auth.PasswordBreachCheckTimeout,
+		BreachCountLimit:   cfg.Auth.PasswordBreachCountLimit,
+	})
+
+	// Calibrate the bcrypt cost once at startup rather than trusting a
+	// hardcoded constant, so hash time tracks this host's actual CPU speed.
+	bcryptCost := cfg.Auth.BCryptCost
+	if cfg.Auth.PasswordHashCalibrate {
+		bcryptCost = utils.CalibrateBcryptCost(cfg.Auth.PasswordHashTarget)
+		log.Printf("password hashing: calibrated bcrypt cost %d (target %s)", bcryptCost, cfg.Auth.PasswordHashTarget)
+	}
+	passwordHasher := utils.NewBcryptHasher(bcryptCost)
+
+	// External auth sources (LDAP, OIDC ROPC) Authenticate falls back to
+	// once the local password check fails, tried in cfg.ExternalAuth.Priority
+	// order.
+	loginProviders := auth.NewLoginProviders(cfg.ExternalAuth)
+
+	userService := service.NewUserService(userRepo, identityRepo, totpRepo, auditRepo, tx, cfg, passwordPolicy, passwordHasher, loginProviders, eventOutbox, eventBus)
+
+	// Scheduled maintenance jobs, serialized across app instances via a
+	// Postgres advisory lock so a multi-replica deployment doesn't double-run
+	// them.
+	maintenanceLocker := maintenance.NewPostgresLocker(db)
+	maintenanceScheduler := maintenance.NewScheduler(maintenanceLocker, cfg.Maintenance.Interval,
+		maintenance.NewCleanupUnverifiedUsers(userRepo, cfg.Maintenance.UnverifiedRetentionDays),
+		maintenance.NewExpireSuspensions(userRepo),
+		maintenance.NewPurgeSoftDeleted(userRepo, cfg.Maintenance.SoftDeleteRetentionDays),
+	)
+	if cfg.Maintenance.Enabled {
+		maintenanceCtx, cancelMaintenance := context.WithCancel(context.Background())
+		defer cancelMaintenance()
+		go maintenanceScheduler.Start(maintenanceCtx, func(result maintenance.Result) {
+			if result.Err != nil {
+				log.Printf("maintenance: job %s failed after %s: %v", result.Job, result.Duration, result.Err)
+				return
+			}
+			if result.Skipped {
+				log.Printf("maintenance: job %s skipped, lock held elsewhere", result.Job)
+				return
+			}
+			log.Printf("maintenance: job %s affected %d rows in %s", result.Job, result.RowsAffected, result.Duration)
+		})
+	}
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg)
-	rateLimiter := middleware.NewRateLimiter(100, time.Minute)
+	authMiddleware := middleware.NewAuthMiddleware(cfg, sessionRepo, signingKeyRepo)
+	redisOpts, err := redis.ParseURL(cfg.Cache.RedisURL)
+	if err != nil {
+		log.Fatalf("Failed to parse REDIS_URL: %v", err)
+	}
+	rateLimiter := middleware.NewRedisRateLimiter(redis.NewClient(redisOpts), cfg.RateLimit.DefaultLimit, cfg.RateLimit.DefaultWindow)
+	rateLimiter.SetRouteLimit("/api/v1/login", 10, time.Minute)
+	rateLimiter.SetRouteLimit("/api/v1/register", 5, time.Minute)
+	rateLimiter.OnError(func(err error) {
+		log.Printf("rate limiter: redis unavailable: %v", err)
+	})
+	if cfg.RateLimit.FailMode == "closed" {
+		rateLimiter.SetFailMode(middleware.FailClosed)
+	}
+	if len(cfg.RateLimit.TrustedProxies) > 0 {
+		if err := rateLimiter.SetTrustedProxies(cfg.RateLimit.TrustedProxies); err != nil {
+			log.Fatalf("rate limiter: %v", err)
+		}
+	}
+	for path, override := range cfg.RateLimit.Routes {
+		window := time.Duration(override.WindowSeconds) * time.Second
+		if override.Burst > 0 {
+			rateLimiter.SetRouteTokenBucket(path, override.Limit, window, override.Burst)
+		} else {
+			rateLimiter.SetRouteLimit(path, override.Limit, window)
+		}
+	}
+
+	// Initialize handlers
+	userHandler := handler.NewUserHandler(userService, authMiddleware)
+	authHandler := handler.NewAuthHandler(userService, authMiddleware)
+	oauthHandler := handler.NewOAuthHandler(cfg, userService, authMiddleware)
+	sessionHandler := handler.NewSessionHandler(sessionRepo)
+	auditHandler := handler.NewAuditHandler(auditRepo)
+	jwksHandler := handler.NewJWKSHandler(authMiddleware)
+	emailWebhookHandler := handler.NewEmailWebhookHandler(emailService)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceScheduler)
+	authSourceHandler := handler.NewAuthSourceHandler(loginProviders, userService)
 
 	// Create router
 	router := gin.Default()
 
 	// Global middleware
+	router.Use(middleware.ErrorResponder())
 	router.Use(rateLimiter.Middleware())
 
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// Public routes
 	public := router.Group("/api/v1")
 	{
 		public.POST("/register", userHandler.CreateUser)
-		public.POST("/login", func(c *gin.Context) {
-			// Login handler would go here
-		})
 		public.POST("/refresh-token", authMiddleware.RefreshToken())
+		authHandler.RegisterRoutes(public)
+		oauthHandler.RegisterRoutes(public)
+		public.POST("/webhooks/email", emailWebhookHandler.HandleEvent)
 	}
 
 	// Protected routes
 	protected := router.Group("/api/v1")
 	protected.Use(authMiddleware.Authenticate())
+	// rateLimiter above already limited this request by IP (user_id isn't
+	// set yet at that point); run it again now that Authenticate has set
+	// user_id, so authenticated clients get their own per-user budget
+	// instead of sharing one IP bucket with everyone behind the same NAT.
+	protected.Use(rateLimiter.Middleware())
 	{
 		userHandler.RegisterRoutes(protected)
+		authHandler.RegisterProtectedRoutes(protected)
+		oauthHandler.RegisterProtectedRoutes(protected)
+		authSourceHandler.RegisterProtectedRoutes(protected)
+		protected.POST("/logout", authMiddleware.Logout())
+		protected.POST("/logout-all", authMiddleware.LogoutAll())
 	}
 
 	// Admin routes
 	admin := router.Group("/api/v1/admin")
 	admin.Use(authMiddleware.Authenticate())
+	admin.Use(rateLimiter.Middleware())
 	admin.Use(authMiddleware.RequireAdmin())
 	{
 		admin.GET("/users/stats", userHandler.GetStats)
+		admin.GET("/users/:id/sessions", sessionHandler.ListUserSessions)
+		admin.GET("/audit-logs", auditHandler.ListAuditLogs)
+		admin.GET("/maintenance/jobs", maintenanceHandler.ListJobs)
+		admin.POST("/maintenance/jobs/:name/trigger", maintenanceHandler.TriggerJob)
 	}
 
 	// Start server
@@ -0,0 +1,13 @@
+// maintenance/job.go - Scheduled maintenance job contract
+
+package maintenance
+
+import "context"
+
+// Job is a unit of scheduled maintenance work, run periodically by
+// Scheduler and triggerable on demand via an admin endpoint. Run returns
+// how many rows it affected, for Result's metrics.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) (rowsAffected int64, err error)
+}
@@ -0,0 +1,63 @@
+// maintenance/jobs.go - Built-in maintenance jobs
+
+package maintenance
+
+import (
+	"context"
+	"time"
+
+	"myapp/repository"
+)
+
+// CleanupUnverifiedUsers deletes StatusPending users who never verified
+// their email within RetentionDays of registering.
+type CleanupUnverifiedUsers struct {
+	userRepo      *repository.UserRepository
+	retentionDays int
+}
+
+func NewCleanupUnverifiedUsers(userRepo *repository.UserRepository, retentionDays int) *CleanupUnverifiedUsers {
+	return &CleanupUnverifiedUsers{userRepo: userRepo, retentionDays: retentionDays}
+}
+
+func (j *CleanupUnverifiedUsers) Name() string { return "cleanup_unverified_users" }
+
+func (j *CleanupUnverifiedUsers) Run(ctx context.Context) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -j.retentionDays)
+	return j.userRepo.DeleteUnverifiedOlderThan(ctx, cutoff)
+}
+
+// ExpireSuspensions reactivates every user whose suspension's SuspendedUntil
+// has elapsed.
+type ExpireSuspensions struct {
+	userRepo *repository.UserRepository
+}
+
+func NewExpireSuspensions(userRepo *repository.UserRepository) *ExpireSuspensions {
+	return &ExpireSuspensions{userRepo: userRepo}
+}
+
+func (j *ExpireSuspensions) Name() string { return "expire_suspensions" }
+
+func (j *ExpireSuspensions) Run(ctx context.Context) (int64, error) {
+	return j.userRepo.ExpireSuspensions(ctx, time.Now())
+}
+
+// PurgeSoftDeleted hard-deletes user rows that have sat soft-deleted for
+// longer than RetentionDays, so DeleteUser's tombstone doesn't accumulate
+// forever.
+type PurgeSoftDeleted struct {
+	userRepo      *repository.UserRepository
+	retentionDays int
+}
+
+func NewPurgeSoftDeleted(userRepo *repository.UserRepository, retentionDays int) *PurgeSoftDeleted {
+	return &PurgeSoftDeleted{userRepo: userRepo, retentionDays: retentionDays}
+}
+
+func (j *PurgeSoftDeleted) Name() string { return "purge_soft_deleted" }
+
+func (j *PurgeSoftDeleted) Run(ctx context.Context) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -j.retentionDays)
+	return j.userRepo.PurgeSoftDeleted(ctx, cutoff)
+}
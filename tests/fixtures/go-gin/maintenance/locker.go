@@ -0,0 +1,50 @@
+// maintenance/locker.go - Distributed locking for scheduled jobs
+
+package maintenance
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Locker serializes a scheduled job across multiple app instances sharing
+// one database, so a slow job run doesn't overlap with the next tick, or
+// with the same job running on a second instance.
+type Locker interface {
+	// WithLock runs fn while holding a lock scoped to key. If the lock is
+	// already held elsewhere, fn is not run and acquired is false.
+	WithLock(ctx context.Context, key string, fn func(ctx context.Context) (int64, error)) (rowsAffected int64, acquired bool, err error)
+}
+
+// PostgresLocker backs Locker with a Postgres transaction-scoped advisory
+// lock (pg_try_advisory_xact_lock), so the lock is released automatically
+// when the transaction ends - including if the instance holding it crashes
+// mid-job - without needing an explicit unlock call or a lock table.
+type PostgresLocker struct {
+	db *gorm.DB
+}
+
+func NewPostgresLocker(db *gorm.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+func (l *PostgresLocker) WithLock(ctx context.Context, key string, fn func(ctx context.Context) (int64, error)) (int64, bool, error) {
+	var rows int64
+	var acquired bool
+
+	err := l.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+
+		n, err := fn(ctx)
+		rows = n
+		return err
+	})
+
+	return rows, acquired, err
+}
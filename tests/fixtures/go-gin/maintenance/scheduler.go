@@ -0,0 +1,93 @@
+// maintenance/scheduler.go - Runs registered Jobs on an interval
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Result reports the outcome of one Job run, for logging or metrics export.
+type Result struct {
+	Job          string
+	RowsAffected int64
+	Duration     time.Duration
+	// Skipped is true when another instance held the job's lock, so this
+	// run didn't happen at all rather than happening and failing.
+	Skipped bool
+	Err     error
+}
+
+// Scheduler runs a fixed set of Jobs, each serialized through a Locker so
+// only one app instance executes a given job at a time.
+type Scheduler struct {
+	jobs     []Job
+	locker   Locker
+	interval time.Duration
+}
+
+// NewScheduler builds a Scheduler that sweeps every job in jobs once per
+// interval.
+func NewScheduler(locker Locker, interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{jobs: jobs, locker: locker, interval: interval}
+}
+
+// Start runs every registered job once immediately, then again every
+// interval, until ctx is canceled. onResult is called once per job per
+// tick; a job erroring or being skipped never stops the scheduler or its
+// siblings.
+func (s *Scheduler) Start(ctx context.Context, onResult func(Result)) {
+	s.runAll(ctx, onResult)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runAll(ctx, onResult)
+		}
+	}
+}
+
+func (s *Scheduler) runAll(ctx context.Context, onResult func(Result)) {
+	for _, job := range s.jobs {
+		onResult(s.RunJob(ctx, job))
+	}
+}
+
+// RunJob runs a single job immediately under the scheduler's lock, for both
+// each scheduled tick and a manual admin trigger.
+func (s *Scheduler) RunJob(ctx context.Context, job Job) Result {
+	start := time.Now()
+	rows, acquired, err := s.locker.WithLock(ctx, "maintenance:"+job.Name(), job.Run)
+	return Result{
+		Job:          job.Name(),
+		RowsAffected: rows,
+		Duration:     time.Since(start),
+		Skipped:      !acquired,
+		Err:          err,
+	}
+}
+
+// Trigger runs the named job immediately, for an admin "run now" endpoint.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (Result, error) {
+	for _, job := range s.jobs {
+		if job.Name() == name {
+			return s.RunJob(ctx, job), nil
+		}
+	}
+	return Result{}, fmt.Errorf("maintenance: unknown job %q", name)
+}
+
+// Jobs returns the names of every registered job, for listing what an
+// admin can trigger.
+func (s *Scheduler) Jobs() []string {
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name()
+	}
+	return names
+}
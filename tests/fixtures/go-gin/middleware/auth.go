@@ -3,6 +3,8 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -13,14 +15,54 @@ import (
 
 	"myapp/config"
 	"myapp/models"
+	"myapp/repository"
+	"myapp/role"
+)
+
+// revokedCacheCapacity bounds memory use; it only needs to comfortably
+// exceed the number of logouts expected within a single access token TTL.
+const revokedCacheCapacity = 10000
+
+// pendingTokenExpiry bounds how long a user has to complete a 2FA challenge
+// after verifying their password, before having to log in again.
+const pendingTokenExpiry = 5 * time.Minute
+
+const (
+	tokenPurposeAccess  = "access"
+	tokenPurposePending = "2fa_pending"
 )
 
 type AuthMiddleware struct {
-	config *config.Config
+	config      *config.Config
+	sessionRepo *repository.SessionRepository
+	revoked     *revokedJTICache
+	signingKeys *SigningKeySet
+}
+
+// NewAuthMiddleware wires up token issuance/verification. Access tokens are
+// signed RS256 under a key from signingKeys - persisted via
+// signingKeyRepo and rotated on a schedule - rather than the shared HMAC
+// secret, so a JWKS document can be published for other services to
+// verify tokens with. Refresh tokens stay HS256 under Auth.RefreshSecret:
+// they're never verified outside this service, so there's nothing to gain
+// from asymmetric signing there.
+func NewAuthMiddleware(cfg *config.Config, sessionRepo *repository.SessionRepository, signingKeyRepo *repository.SigningKeyRepository) *AuthMiddleware {
+	signingKeys, err := NewSigningKeySet(context.Background(), signingKeyRepo, cfg.Auth.SigningKeyRotationInterval, cfg.Auth.SigningKeyRetireAfter)
+	if err != nil {
+		panic(fmt.Sprintf("auth: failed to load/generate signing keys: %v", err))
+	}
+
+	return &AuthMiddleware{
+		config:      cfg,
+		sessionRepo: sessionRepo,
+		revoked:     newRevokedJTICache(revokedCacheCapacity, time.Duration(cfg.Auth.AccessTokenExpiry)*time.Second),
+		signingKeys: signingKeys,
+	}
 }
 
-func NewAuthMiddleware(cfg *config.Config) *AuthMiddleware {
-	return &AuthMiddleware{config: cfg}
+// JWKS exposes the currently-valid public keys for access token verification.
+func (m *AuthMiddleware) JWKS() JWKSDocument {
+	return m.signingKeys.JWKS()
 }
 
 // Authenticate validates JWT token and sets user context
@@ -48,10 +90,23 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		if claims.Purpose == tokenPurposePending {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "2fa verification required"})
+			c.Abort()
+			return
+		}
+
+		if claims.Jti != "" && m.revoked.Contains(claims.Jti) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		// Set user context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("jti", claims.Jti)
 
 		c.Next()
 	}
@@ -91,7 +146,66 @@ func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
 	return m.RequireRole(models.RoleAdmin)
 }
 
-// Q: How should we handle token refresh to maintain seamless user sessions?
+// PermissionChecker is a resource-scoped escape hatch for RequirePermission:
+// a request missing one of the required permissions under the role mapping
+// can still be let through if any checker reports true, e.g. self:update
+// passing when the caller owns the resource being acted on.
+type PermissionChecker interface {
+	Check(c *gin.Context) bool
+}
+
+type PermissionCheckerFunc func(c *gin.Context) bool
+
+func (f PermissionCheckerFunc) Check(c *gin.Context) bool {
+	return f(c)
+}
+
+// SelfParamChecker passes when the URL param named param matches the
+// authenticated caller's user ID, e.g. for "/users/:id" self-service routes.
+func SelfParamChecker(param string) PermissionChecker {
+	return PermissionCheckerFunc(func(c *gin.Context) bool {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			return false
+		}
+		id, ok := userID.(uuid.UUID)
+		return ok && c.Param(param) == id.String()
+	})
+}
+
+// RequirePermission checks that the caller's role expands to every listed
+// permission. If any checker is given and reports true, access is granted
+// regardless of the role mapping - the resource-scoped escape hatch.
+func (m *AuthMiddleware) RequirePermission(perms []role.Permission, checkers ...PermissionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole, exists := c.Get("user_role")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+
+		for _, checker := range checkers {
+			if checker.Check(c) {
+				c.Next()
+				return
+			}
+		}
+
+		if !role.Has(models.UserRole(userRole.(string)), perms...) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RefreshToken rotates a refresh token: the presented token is looked up by
+// its jti in the session store, rejected if revoked/expired/unknown, and -
+// if it was already rotated once before - treated as a stolen-token replay,
+// which revokes every session belonging to the user.
 func (m *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		refreshToken := c.GetHeader("X-Refresh-Token")
@@ -108,22 +222,43 @@ func (m *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 			return
 		}
 
-		// Generate new access token
-		accessToken, err := m.generateAccessToken(claims.UserID, claims.Email, claims.Role)
+		ctx := c.Request.Context()
+		session, err := m.sessionRepo.GetByJTI(ctx, claims.Jti)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unknown refresh token"})
+			c.Abort()
+			return
+		}
+
+		if session.ReplacedBy != "" {
+			// This token was already rotated away - presenting it again means
+			// it leaked, so burn every session the user has.
+			_ = m.sessionRepo.RevokeAllForUser(ctx, session.UserID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token reuse detected"})
 			c.Abort()
 			return
 		}
 
-		// Generate new refresh token
-		newRefreshToken, err := m.generateRefreshToken(claims.UserID, claims.Email, claims.Role)
+		if !session.IsActive() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token revoked or expired"})
+			c.Abort()
+			return
+		}
+
+		accessToken, newRefreshToken, err := m.GenerateTokenPair(ctx, claims.UserID, claims.Email, claims.Role, c.Request.UserAgent(), c.ClientIP())
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 			c.Abort()
 			return
 		}
 
+		newClaims, _ := m.parseRefreshToken(newRefreshToken)
+		if err := m.sessionRepo.Rotate(ctx, claims.Jti, newClaims.Jti); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate session"})
+			c.Abort()
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"access_token":  accessToken,
 			"refresh_token": newRefreshToken,
@@ -133,16 +268,65 @@ func (m *AuthMiddleware) RefreshToken() gin.HandlerFunc {
 	}
 }
 
+// Logout revokes the session behind the caller's current access token so it
+// can no longer be refreshed, and blacklists the access token itself until
+// it would have naturally expired.
+func (m *AuthMiddleware) Logout() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jti, _ := c.Get("jti")
+		if jtiStr, ok := jti.(string); ok && jtiStr != "" {
+			m.revoked.Add(jtiStr)
+			_ = m.sessionRepo.Revoke(c.Request.Context(), jtiStr)
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+	}
+}
+
+// LogoutAll revokes every session belonging to the authenticated user.
+func (m *AuthMiddleware) LogoutAll() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			c.Abort()
+			return
+		}
+
+		if jti, ok := c.Get("jti"); ok {
+			if jtiStr, ok := jti.(string); ok && jtiStr != "" {
+				m.revoked.Add(jtiStr)
+			}
+		}
+
+		if err := m.sessionRepo.RevokeAllForUser(c.Request.Context(), userID.(uuid.UUID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "all sessions revoked"})
+	}
+}
+
 type TokenClaims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	Role    string    `json:"role"`
+	Jti     string    `json:"jti,omitempty"`
+	Purpose string    `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (m *AuthMiddleware) parseToken(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(m.config.Auth.JWTSecret), nil
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("token is missing kid header")
+		}
+		public, ok := m.signingKeys.PublicKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return public, nil
 	})
 
 	if err != nil {
@@ -172,26 +356,37 @@ func (m *AuthMiddleware) parseRefreshToken(tokenString string) (*TokenClaims, er
 	return nil, jwt.ErrSignatureInvalid
 }
 
-func (m *AuthMiddleware) generateAccessToken(userID uuid.UUID, email string, role string) (string, error) {
+func (m *AuthMiddleware) generateAccessToken(userID uuid.UUID, email, role, jti string) (string, error) {
 	claims := &TokenClaims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
+		Jti:    jti,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(m.config.Auth.AccessTokenExpiry) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.config.Auth.JWTSecret))
+	return m.signWithActiveKey(claims)
+}
+
+// signWithActiveKey signs claims RS256 under the signing key set's current
+// active key, stamping its kid into the header so parseToken can find the
+// matching public key later even after the key has rotated out of Active.
+func (m *AuthMiddleware) signWithActiveKey(claims *TokenClaims) (string, error) {
+	kid, private := m.signingKeys.Active()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(private)
 }
 
-func (m *AuthMiddleware) generateRefreshToken(userID uuid.UUID, email string, role string) (string, error) {
+func (m *AuthMiddleware) generateRefreshToken(userID uuid.UUID, email, role, jti string) (string, error) {
 	claims := &TokenClaims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
+		Jti:    jti,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(m.config.Auth.RefreshTokenExpiry) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -202,17 +397,66 @@ func (m *AuthMiddleware) generateRefreshToken(userID uuid.UUID, email string, ro
 	return token.SignedString([]byte(m.config.Auth.RefreshSecret))
 }
 
-// GenerateTokenPair creates both access and refresh tokens
-func (m *AuthMiddleware) GenerateTokenPair(userID uuid.UUID, email string, role string) (string, string, error) {
-	accessToken, err := m.generateAccessToken(userID, email, role)
+// GenerateTokenPair creates an access/refresh token pair sharing a single
+// jti, and persists a RefreshSession row so the pair can later be looked
+// up, rotated, or revoked.
+func (m *AuthMiddleware) GenerateTokenPair(ctx context.Context, userID uuid.UUID, email, role, userAgent, ip string) (string, string, error) {
+	jti := uuid.New().String()
+
+	accessToken, err := m.generateAccessToken(userID, email, role, jti)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := m.generateRefreshToken(userID, email, role)
+	refreshToken, err := m.generateRefreshToken(userID, email, role, jti)
 	if err != nil {
 		return "", "", err
 	}
 
+	now := time.Now()
+	session := &models.RefreshSession{
+		JTI:       jti,
+		UserID:    userID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Duration(m.config.Auth.RefreshTokenExpiry) * time.Second),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+	if err := m.sessionRepo.Create(ctx, session); err != nil {
+		return "", "", err
+	}
+
 	return accessToken, refreshToken, nil
 }
+
+// GeneratePendingToken issues a short-lived, purpose-scoped token proving
+// the caller already passed password verification but still owes a 2FA
+// code. It is rejected by Authenticate and must be exchanged via the 2FA
+// login endpoint for a real token pair.
+func (m *AuthMiddleware) GeneratePendingToken(userID uuid.UUID, email, role string) (string, error) {
+	claims := &TokenClaims{
+		UserID:  userID,
+		Email:   email,
+		Role:    role,
+		Purpose: tokenPurposePending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(pendingTokenExpiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return m.signWithActiveKey(claims)
+}
+
+// ParsePendingToken validates a token minted by GeneratePendingToken,
+// rejecting anything that isn't pending-2FA scoped.
+func (m *AuthMiddleware) ParsePendingToken(tokenString string) (*TokenClaims, error) {
+	claims, err := m.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != tokenPurposePending {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
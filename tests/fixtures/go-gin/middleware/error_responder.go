@@ -0,0 +1,50 @@
+// middleware/error_responder.go - Uniform JSON error rendering
+
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapp/service"
+)
+
+// ErrorResponder renders a single uniform JSON body for any error a
+// handler pushed via c.Error(err), so handlers can stop hand-mapping
+// service sentinels to HTTP statuses themselves. It must be registered
+// before any handler that calls c.Error, and does nothing if a handler
+// already wrote its own response.
+func ErrorResponder() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := uuid.New().String()
+		c.Set("request_id", requestID)
+
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+
+		err := c.Errors.Last().Err
+
+		var svcErr *service.Error
+		if errors.As(err, &svcErr) {
+			c.JSON(svcErr.Status, gin.H{
+				"code":       svcErr.Code,
+				"message":    svcErr.Message,
+				"details":    svcErr.Details,
+				"request_id": requestID,
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":       "internal_error",
+			"message":    "internal server error",
+			"request_id": requestID,
+		})
+	}
+}
@@ -10,6 +10,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Limiter is the surface shared by every rate limiter in this package
+// (RateLimiter, IPRateLimiter, SlidingWindowRateLimiter, RedisRateLimiter),
+// so a route group can be handed whichever implementation fits it without
+// the caller needing to know which one.
+type Limiter interface {
+	Middleware() gin.HandlerFunc
+}
+
 type RateLimiter struct {
 	requests map[string]*clientRequests
 	mu       sync.RWMutex
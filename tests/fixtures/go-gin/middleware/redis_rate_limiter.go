@@ -0,0 +1,383 @@
+// middleware/redis_rate_limiter.go - Distributed rate limiting (sliding window + token bucket) backed by Redis
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Algorithm selects which Lua script enforces a RouteLimit.
+type Algorithm int
+
+const (
+	// SlidingWindow admits at most Limit requests in any trailing Window -
+	// exact, but allows no burst above Limit. This is the default.
+	SlidingWindow Algorithm = iota
+	// TokenBucket refills Burst tokens at a steady Limit-per-Window rate,
+	// so a client can spend a burst of up to Burst requests at once and
+	// then settles back to the steady rate. Burst must be set for this
+	// algorithm to make sense; see RedisRateLimiter.SetRouteTokenBucket.
+	TokenBucket
+)
+
+// FailMode controls what happens to a request when Redis is unreachable.
+type FailMode int
+
+const (
+	// FailOpen admits the request, so a Redis outage doesn't take the
+	// whole API down with it. This is the default.
+	FailOpen FailMode = iota
+	// FailClosed rejects the request instead, trading availability for
+	// never letting an outage erase the rate limit.
+	FailClosed
+)
+
+// RouteLimit is the request budget enforced for one matched route: at most
+// Limit requests per Window, per client. Burst only applies to the
+// TokenBucket algorithm.
+type RouteLimit struct {
+	Limit     int
+	Window    time.Duration
+	Burst     int
+	Algorithm Algorithm
+}
+
+// slidingWindowScript enforces the limit atomically so concurrent requests
+// from the same client, arriving at different app instances, can't both
+// read a stale count and slip through. It trims entries that have aged out
+// of the window, then admits the request only if the trimmed count is still
+// under the limit.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+if count >= limit then
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if #oldest == 0 then
+		return {0, count, now}
+	end
+	return {0, count, tonumber(oldest[2])}
+end
+
+redis.call('ZADD', key, now, member)
+redis.call('PEXPIRE', key, window)
+return {1, count + 1, now}
+`)
+
+// tokenBucketScript implements a Redis-backed token bucket: tokens refill
+// continuously at limit/window per second, capped at burst, stored as a
+// hash {tokens, updated_at} keyed per client+route. A fresh key starts
+// full, so the first request after idle time never waits.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local burst = tonumber(ARGV[4])
+
+local rate = limit / window
+local data = redis.call('HMGET', key, 'tokens', 'updated_at')
+local tokens = tonumber(data[1])
+local updatedAt = tonumber(data[2])
+
+if tokens == nil then
+	tokens = burst
+	updatedAt = now
+end
+
+local elapsed = math.max(0, now - updatedAt)
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+local retryMs = 0
+if tokens >= 1 then
+	allowed = 1
+	tokens = tokens - 1
+else
+	retryMs = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HMSET', key, 'tokens', tokens, 'updated_at', now)
+redis.call('PEXPIRE', key, math.ceil(window * 1000))
+
+return {allowed, math.floor(tokens), retryMs}
+`)
+
+// RedisRateLimiter enforces a per-route request budget per client using
+// Redis, so the count is shared across every instance behind the load
+// balancer instead of being reset whenever a request lands on a different
+// pod. Routes without an explicit SetRouteLimit/SetRouteTokenBucket fall
+// back to the default budget passed to NewRedisRateLimiter.
+type RedisRateLimiter struct {
+	client         *redis.Client
+	def            RouteLimit
+	routes         map[string]RouteLimit
+	trustedProxies []*net.IPNet
+	identifierFn   func(c *gin.Context) string
+	failMode       FailMode
+	mu             sync.RWMutex
+	onError        func(error)
+}
+
+func NewRedisRateLimiter(client *redis.Client, defaultLimit int, defaultWindow time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client: client,
+		def:    RouteLimit{Limit: defaultLimit, Window: defaultWindow},
+		routes: make(map[string]RouteLimit),
+	}
+}
+
+// SetRouteLimit overrides the sliding-window budget for one route pattern,
+// e.g. "/api/v1/login", as registered with gin (c.FullPath()).
+func (rl *RedisRateLimiter) SetRouteLimit(path string, limit int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.routes[path] = RouteLimit{Limit: limit, Window: window, Algorithm: SlidingWindow}
+}
+
+// SetRouteTokenBucket overrides the budget for one route pattern with the
+// token-bucket algorithm, letting a client spend up to burst requests at
+// once before settling back to the steady limit-per-window rate.
+func (rl *RedisRateLimiter) SetRouteTokenBucket(path string, limit int, window time.Duration, burst int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.routes[path] = RouteLimit{Limit: limit, Window: window, Burst: burst, Algorithm: TokenBucket}
+}
+
+// SetTrustedProxies declares which immediate peers (CIDRs, or bare IPs
+// treated as /32) are allowed to set X-Forwarded-For. A request whose
+// direct peer isn't in this list always falls back to c.ClientIP() - an
+// unauthenticated client can't spoof its way into someone else's bucket
+// just by sending the header.
+func (rl *RedisRateLimiter) SetTrustedProxies(cidrs []string) error {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			cidr += "/32"
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("rate limiter: invalid trusted proxy %q: %w", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	rl.mu.Lock()
+	rl.trustedProxies = nets
+	rl.mu.Unlock()
+	return nil
+}
+
+// SetIdentifierFunc overrides how a request is attributed to a client,
+// replacing the default apiKey->user->IP chain (see getIdentifier).
+func (rl *RedisRateLimiter) SetIdentifierFunc(fn func(c *gin.Context) string) {
+	rl.mu.Lock()
+	rl.identifierFn = fn
+	rl.mu.Unlock()
+}
+
+// SetFailMode controls whether a request is admitted (FailOpen, the
+// default) or rejected (FailClosed) while Redis is unreachable.
+func (rl *RedisRateLimiter) SetFailMode(mode FailMode) {
+	rl.mu.Lock()
+	rl.failMode = mode
+	rl.mu.Unlock()
+}
+
+// OnError registers a callback invoked whenever Redis is unreachable.
+func (rl *RedisRateLimiter) OnError(fn func(error)) {
+	rl.onError = fn
+}
+
+func (rl *RedisRateLimiter) routeLimit(path string) RouteLimit {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	if limit, ok := rl.routes[path]; ok {
+		return limit
+	}
+	return rl.def
+}
+
+func (rl *RedisRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := rl.routeLimit(c.FullPath())
+		identifier := rl.getIdentifier(c)
+		key := fmt.Sprintf("ratelimit:{%s}:%s", c.FullPath(), identifier)
+
+		allowed, remaining, resetAt, err := rl.allow(c.Request.Context(), key, route)
+		if err != nil {
+			if rl.onError != nil {
+				rl.onError(err)
+			}
+			rl.mu.RLock()
+			failMode := rl.failMode
+			rl.mu.RUnlock()
+			if failMode == FailClosed {
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"error": "rate limiter unavailable",
+					"code":  "RATE_LIMITER_UNAVAILABLE",
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(route.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+		if !allowed {
+			c.Header("Retry-After", strconv.FormatInt(int64(time.Until(resetAt).Seconds()), 10))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit exceeded",
+				"code":  "RATE_LIMITED",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// getIdentifier attributes a request to a client, trying an API key first
+// (so machine clients get their own budget regardless of IP or login
+// state), then the authenticated user, then the client IP. Callers that
+// need a different precedence can override it with SetIdentifierFunc.
+func (rl *RedisRateLimiter) getIdentifier(c *gin.Context) string {
+	rl.mu.RLock()
+	fn := rl.identifierFn
+	rl.mu.RUnlock()
+	if fn != nil {
+		return fn(c)
+	}
+
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return "apikey:" + apiKey
+	}
+	if raw, exists := c.Get("user_id"); exists {
+		if userID, ok := raw.(uuid.UUID); ok {
+			return "user:" + userID.String()
+		}
+	}
+	return "ip:" + rl.clientIP(c)
+}
+
+// clientIP resolves c.ClientIP() unless the immediate peer is a declared
+// trusted proxy, in which case it walks X-Forwarded-For from the right,
+// skipping hops that are themselves trusted proxies, and uses the first one
+// that isn't - the address a trusted proxy appended, which a client can't
+// forge by sending its own header.
+func (rl *RedisRateLimiter) clientIP(c *gin.Context) string {
+	rl.mu.RLock()
+	trusted := rl.trustedProxies
+	rl.mu.RUnlock()
+	if len(trusted) == 0 {
+		return c.ClientIP()
+	}
+
+	remoteIP := net.ParseIP(stripPort(c.Request.RemoteAddr))
+	if remoteIP == nil || !ipTrusted(remoteIP, trusted) {
+		return c.ClientIP()
+	}
+
+	xff := c.Request.Header.Get("X-Forwarded-For")
+	if xff == "" {
+		return c.ClientIP()
+	}
+	hops := strings.Split(xff, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			break
+		}
+		if !ipTrusted(ip, trusted) {
+			return hop
+		}
+	}
+	return c.ClientIP()
+}
+
+func ipTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	for _, ipNet := range trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripPort(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// allow dispatches to the Lua script matching route.Algorithm and returns
+// whether the request is admitted, the remaining budget after this call,
+// and the time used for both X-RateLimit-Reset and Retry-After.
+func (rl *RedisRateLimiter) allow(ctx context.Context, key string, route RouteLimit) (bool, int, time.Time, error) {
+	if route.Algorithm == TokenBucket {
+		return rl.allowTokenBucket(ctx, key, route)
+	}
+	return rl.allowSlidingWindow(ctx, key, route)
+}
+
+func (rl *RedisRateLimiter) allowSlidingWindow(ctx context.Context, key string, route RouteLimit) (bool, int, time.Time, error) {
+	now := time.Now()
+	res, err := slidingWindowScript.Run(ctx, rl.client, []string{key},
+		now.UnixMilli(), route.Window.Milliseconds(), route.Limit, uuid.NewString(),
+	).Slice()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+
+	admitted := res[0].(int64) == 1
+	count := res[1].(int64)
+	oldestMs := res[2].(int64)
+
+	remaining := route.Limit - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+	resetAt := time.UnixMilli(oldestMs).Add(route.Window)
+
+	return admitted, remaining, resetAt, nil
+}
+
+func (rl *RedisRateLimiter) allowTokenBucket(ctx context.Context, key string, route RouteLimit) (bool, int, time.Time, error) {
+	now := time.Now()
+	res, err := tokenBucketScript.Run(ctx, rl.client, []string{key},
+		float64(now.UnixNano())/1e9, route.Window.Seconds(), route.Limit, route.Burst,
+	).Slice()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+
+	admitted := res[0].(int64) == 1
+	remaining := int(res[1].(int64))
+	retryMs := res[2].(int64)
+
+	return admitted, remaining, now.Add(time.Duration(retryMs) * time.Millisecond), nil
+}
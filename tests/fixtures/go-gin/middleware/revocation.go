@@ -0,0 +1,75 @@
+// middleware/revocation.go - Bounded in-memory cache of revoked access-token jtis
+
+package middleware
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// revokedJTICache lets Authenticate() reject a just-revoked access token
+// immediately, without a DB round trip on every request. It only needs to
+// hold entries until the token's own expiry, after which Authenticate()
+// would reject it anyway - so a small bounded LRU is enough; we don't need
+// to persist this anywhere.
+type revokedJTICache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type revokedEntry struct {
+	jti       string
+	expiresAt time.Time
+}
+
+func newRevokedJTICache(capacity int, ttl time.Duration) *revokedJTICache {
+	return &revokedJTICache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *revokedJTICache) Add(jti string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[jti]; ok {
+		c.order.MoveToFront(el)
+		el.Value.(*revokedEntry).expiresAt = time.Now().Add(c.ttl)
+		return
+	}
+
+	el := c.order.PushFront(&revokedEntry{jti: jti, expiresAt: time.Now().Add(c.ttl)})
+	c.entries[jti] = el
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*revokedEntry).jti)
+	}
+}
+
+func (c *revokedJTICache) Contains(jti string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[jti]
+	if !ok {
+		return false
+	}
+	if time.Now().After(el.Value.(*revokedEntry).expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, jti)
+		return false
+	}
+	return true
+}
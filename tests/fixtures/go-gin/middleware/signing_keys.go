@@ -0,0 +1,227 @@
+// middleware/signing_keys.go - Rotating RSA signing keys for access tokens
+
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"myapp/models"
+	"myapp/repository"
+)
+
+const signingKeyBits = 2048
+
+// signingKey is one generation of RSA keypair, identified by kid. A key
+// stays valid for verification until retireAt, well past the point a new
+// key becomes active, so tokens signed just before a rotation keep working.
+type signingKey struct {
+	kid       string
+	private   *rsa.PrivateKey
+	createdAt time.Time
+	retireAt  time.Time
+}
+
+// SigningKeySet issues access tokens under a periodically rotated RSA key
+// and publishes every not-yet-retired public key as a JWKS document, so
+// other services can verify tokens without calling back into this one.
+// Every key is persisted via repo as it's generated, and the live set is
+// reloaded from there at startup - so a process restart keeps verifying
+// tokens it already issued, and every replica in a multi-instance
+// deployment signs with (and publishes a JWKS for) the same keys instead
+// of each minting its own. Rotation runs on a background goroutine for the
+// lifetime of the process.
+type SigningKeySet struct {
+	mu               sync.RWMutex
+	keys             []*signingKey
+	active           *signingKey
+	rotationInterval time.Duration
+	retireAfter      time.Duration
+	repo             *repository.SigningKeyRepository
+}
+
+// NewSigningKeySet loads any not-yet-retired keys repo already has - so a
+// restart or a second replica recovers the same key material other
+// instances are signing with and verifying against - generating and
+// persisting an initial key only if none exist yet. rotationInterval and
+// retireAfter fall back to 24h/48h if left at zero.
+func NewSigningKeySet(ctx context.Context, repo *repository.SigningKeyRepository, rotationInterval, retireAfter time.Duration) (*SigningKeySet, error) {
+	if rotationInterval <= 0 {
+		rotationInterval = 24 * time.Hour
+	}
+	if retireAfter <= 0 {
+		retireAfter = 48 * time.Hour
+	}
+
+	ks := &SigningKeySet{
+		rotationInterval: rotationInterval,
+		retireAfter:      retireAfter,
+		repo:             repo,
+	}
+
+	stored, err := repo.ListLive(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("loading persisted signing keys: %w", err)
+	}
+
+	for _, row := range stored {
+		private, err := parsePrivateKeyPEM(row.PrivateKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("parsing persisted signing key %s: %w", row.Kid, err)
+		}
+		ks.keys = append(ks.keys, &signingKey{
+			kid:       row.Kid,
+			private:   private,
+			createdAt: row.CreatedAt,
+			retireAt:  row.RetireAt,
+		})
+	}
+	if len(ks.keys) > 0 {
+		// ListLive orders newest first, so the first row is the most
+		// recently generated key.
+		ks.active = ks.keys[0]
+	} else if _, err := ks.rotate(ctx); err != nil {
+		return nil, err
+	}
+
+	go ks.rotateLoop()
+
+	return ks, nil
+}
+
+func (ks *SigningKeySet) rotateLoop() {
+	ticker := time.NewTicker(ks.rotationInterval)
+	for range ticker.C {
+		// A generation failure leaves the current active key in place;
+		// it'll retry on the next tick rather than leaving the service
+		// with no signing key at all.
+		_, _ = ks.rotate(context.Background())
+	}
+}
+
+// rotate generates a new active key, persists it via repo, and prunes any
+// key whose retirement has passed from both the in-memory set and the
+// backing table.
+func (ks *SigningKeySet) rotate(ctx context.Context) (*signingKey, error) {
+	private, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+
+	now := time.Now()
+	key := &signingKey{
+		kid:       fmt.Sprintf("%d", now.UnixNano()),
+		private:   private,
+		createdAt: now,
+		retireAt:  now.Add(ks.rotationInterval + ks.retireAfter),
+	}
+
+	if err := ks.repo.Create(ctx, &models.SigningKey{
+		Kid:           key.kid,
+		PrivateKeyPEM: encodePrivateKeyPEM(private),
+		CreatedAt:     key.createdAt,
+		RetireAt:      key.retireAt,
+	}); err != nil {
+		return nil, fmt.Errorf("persisting signing key: %w", err)
+	}
+	// Best-effort: a failure here only means a retired row lingers in the
+	// table until the next rotation tries again, not that verification or
+	// issuance is affected.
+	_ = ks.repo.DeleteRetired(ctx, now)
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.active = key
+	ks.keys = append(ks.keys, key)
+
+	live := ks.keys[:0]
+	for _, k := range ks.keys {
+		if now.Before(k.retireAt) {
+			live = append(live, k)
+		}
+	}
+	ks.keys = live
+
+	return key, nil
+}
+
+// Active returns the key new tokens should be signed with.
+func (ks *SigningKeySet) Active() (kid string, private *rsa.PrivateKey) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.active.kid, ks.active.private
+}
+
+// PublicKey looks up the public half of a key by kid, for verifying a
+// token signed by a key that may since have rotated out of Active.
+func (ks *SigningKeySet) PublicKey(kid string) (*rsa.PublicKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	for _, k := range ks.keys {
+		if k.kid == kid {
+			return &k.private.PublicKey, true
+		}
+	}
+	return nil, false
+}
+
+// encodePrivateKeyPEM serializes private as a PKCS1 PEM block for storage.
+func encodePrivateKeyPEM(private *rsa.PrivateKey) string {
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(private),
+	}
+	return string(pem.EncodeToMemory(block))
+}
+
+func parsePrivateKeyPEM(raw string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// JWK is the RFC 7517 representation of one RSA public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSDocument is served at the JWKS endpoint so verifiers can fetch every
+// currently-valid public key.
+type JWKSDocument struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS builds the JWKS document for every key that hasn't yet retired.
+func (ks *SigningKeySet) JWKS() JWKSDocument {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	doc := JWKSDocument{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		doc.Keys = append(doc.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: k.kid,
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(k.private.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.private.PublicKey.E)).Bytes()),
+		})
+	}
+	return doc
+}
@@ -0,0 +1,50 @@
+// models/audit_log.go - Admin-visible activity trail
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditActionUserCreate AuditAction = "user.create"
+	AuditActionUserUpdate AuditAction = "user.update"
+	AuditActionUserDelete AuditAction = "user.delete"
+	AuditActionRoleChange AuditAction = "user.role_change"
+	AuditActionLogin      AuditAction = "user.login"
+	AuditActionLogout     AuditAction = "user.logout"
+	AuditAction2FAEnable  AuditAction = "user.2fa_enable"
+	AuditActionAnonymize  AuditAction = "user.anonymize"
+)
+
+// AuditLog is written inside the same transaction as the mutation it
+// records, so a user row never changes without a matching audit entry.
+// Metadata is a JSON-encoded string rather than a typed column, following
+// the same convention as UserIdentity.RawFields.
+//
+// Recommended index for this table: a composite (target_id, created_at)
+// for "show me this user's history", plus (actor_id, created_at) for
+// "show me what this admin did" - both covering the cursor predicate
+// (created_at, id) used by AuditRepository.List.
+type AuditLog struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key" json:"id"`
+	ActorID   *uuid.UUID  `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	Action    AuditAction `gorm:"size:50;index;not null" json:"action"`
+	TargetID  *uuid.UUID  `gorm:"type:uuid;index" json:"target_id,omitempty"`
+	Metadata  string      `gorm:"type:text" json:"metadata,omitempty"`
+	IP        string      `json:"ip,omitempty"`
+	UserAgent string      `json:"user_agent,omitempty"`
+	CreatedAt time.Time   `gorm:"index" json:"created_at"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// models/email_job.go - Durable send queue backing email.Queue
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmailJob is one outbound email queued for async delivery. Unlike
+// OutboxEvent - which just needs to be claimed once and published - a job
+// here also tracks delivery attempts and its own backoff schedule, since
+// retrying against a flaky SMTP relay (rather than republishing once to a
+// Bus) is what this table exists for. Headers is a JSON-encoded
+// map[string]string, following the same convention as AuditLog.Metadata.
+type EmailJob struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	ToAddress      string     `gorm:"size:320;not null" json:"to"`
+	FromAddress    string     `gorm:"size:320;not null" json:"from"`
+	Subject        string     `gorm:"size:500;not null" json:"subject"`
+	Body           string     `gorm:"type:text;not null" json:"-"`
+	Headers        string     `gorm:"type:text" json:"-"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index;not null" json:"next_attempt_at"`
+	LastError      string     `gorm:"type:text" json:"-"`
+	SentAt         *time.Time `gorm:"index" json:"sent_at,omitempty"`
+	DeadLetteredAt *time.Time `gorm:"index" json:"dead_lettered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (j *EmailJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// models/email_suppression.go - Addresses excluded from future sends
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SuppressionReason string
+
+const (
+	SuppressionBounce    SuppressionReason = "bounce"
+	SuppressionComplaint SuppressionReason = "complaint"
+)
+
+// EmailSuppression records an address our provider has told us to stop
+// mailing, so EmailService can skip it before spending a send attempt.
+type EmailSuppression struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	Email     string            `gorm:"uniqueIndex;not null" json:"email"`
+	Reason    SuppressionReason `gorm:"size:20;not null" json:"reason"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func (s *EmailSuppression) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// models/identity.go - Linked SSO identities
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserIdentity links a models.User to a (provider, subject) pair returned by
+// an OAuth2/OIDC login, so a single account can sign in through more than
+// one provider. RawFields is the provider's userinfo payload, serialized as
+// JSON, kept around for profile backfill and debugging.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Provider  string    `gorm:"size:50;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject   string    `gorm:"size:255;uniqueIndex:idx_provider_subject" json:"subject"`
+	RawFields string    `gorm:"type:text" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
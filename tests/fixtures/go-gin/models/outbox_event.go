@@ -0,0 +1,32 @@
+// models/outbox_event.go - Durable event queue backing events.Dispatcher
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxEvent is written inside the same transaction as the state change
+// that caused it (see events.Outbox.WriteTx), so an event is never lost to
+// a crash between committing that change and publishing it.
+// events.Dispatcher polls for unprocessed rows and republishes each at
+// least once. Payload is a JSON-encoded string, following the same
+// convention as AuditLog.Metadata and UserIdentity.RawFields.
+type OutboxEvent struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	EventType   string     `gorm:"size:100;index;not null" json:"event_type"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Attempts    int        `gorm:"default:0" json:"attempts"`
+	ProcessedAt *time.Time `gorm:"index" json:"processed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// models/session.go - Persisted refresh-token sessions
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshSession is the server-side record behind a refresh token, keyed by
+// the random jti embedded in its claims. Storing it lets us revoke a single
+// device, revoke everything for a user, and detect rotation reuse.
+type RefreshSession struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	JTI        string     `gorm:"size:36;uniqueIndex;not null" json:"jti"`
+	UserID     uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	IssuedAt   time.Time  `json:"issued_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `gorm:"size:36" json:"replaced_by,omitempty"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent,omitempty"`
+	IP         string     `gorm:"size:45" json:"ip,omitempty"`
+}
+
+func (s *RefreshSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+func (s *RefreshSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
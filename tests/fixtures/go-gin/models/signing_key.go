@@ -0,0 +1,18 @@
+// models/signing_key.go - Persisted RSA keys backing middleware.SigningKeySet
+
+package models
+
+import "time"
+
+// SigningKey is one generation of RSA keypair middleware.SigningKeySet
+// issues access tokens under, persisted so a process restart - or a second
+// replica in a multi-instance deployment - recovers the same key material
+// instead of minting its own, which would invalidate every token already
+// issued and serve a JWKS that doesn't match what sibling instances sign
+// with. PrivateKeyPEM is PKCS1-encoded.
+type SigningKey struct {
+	Kid           string    `gorm:"primary_key;size:32" json:"kid"`
+	PrivateKeyPEM string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+	RetireAt      time.Time `gorm:"index;not null" json:"retire_at"`
+}
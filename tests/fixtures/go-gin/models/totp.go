@@ -0,0 +1,56 @@
+// models/totp.go - TOTP-based two-factor authentication
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserTOTP holds a user's enrolled TOTP secret. EncryptedSecret is AES-GCM
+// ciphertext, never the raw base32 secret - see utils.EncryptTOTPSecret.
+// ConfirmedAt is nil until the user proves possession of the secret by
+// submitting one valid code, at which point 2FA becomes enforced at login.
+// LastUsedStep is the TOTP time-step (see utils.ValidateTOTPCode) of the
+// most recently accepted code, so a code intercepted in transit can't be
+// replayed again within its ±1-step skew window - a re-submission has to
+// land on a later step to be accepted.
+type UserTOTP struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;uniqueIndex" json:"user_id"`
+	EncryptedSecret string     `gorm:"type:text;not null" json:"-"`
+	LastUsedStep    int64      `gorm:"not null;default:0" json:"-"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (t *UserTOTP) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+func (t *UserTOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// TOTPRecoveryCode is a single-use fallback code, bcrypt-hashed like a
+// password, issued ten-at-a-time on enrollment.
+type TOTPRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:100;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (c *TOTPRecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
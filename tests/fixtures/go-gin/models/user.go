@@ -37,14 +37,20 @@ type User struct {
 	EmailVerified bool       `gorm:"default:false" json:"email_verified"`
 	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
 	LastLoginIP   string     `json:"last_login_ip,omitempty"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	AnonymizedAt  *time.Time `json:"anonymized_at,omitempty"`
+	// SuspendedUntil is when a suspended user should be auto-reactivated by
+	// maintenance.ExpireSuspensions. Nil means the suspension (if any) has
+	// no set expiry and must be lifted manually.
+	SuspendedUntil *time.Time     `json:"suspended_until,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
 	Profile     *UserProfile     `gorm:"foreignKey:UserID" json:"profile,omitempty"`
 	Preferences *UserPreferences `gorm:"foreignKey:UserID" json:"preferences,omitempty"`
 	Addresses   []UserAddress    `gorm:"foreignKey:UserID" json:"addresses,omitempty"`
+	TOTP        *UserTOTP        `gorm:"foreignKey:UserID" json:"totp,omitempty"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
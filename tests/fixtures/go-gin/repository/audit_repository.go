@@ -0,0 +1,118 @@
+// repository/audit_repository.go - Admin audit log repository
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// WithTx returns a repository bound to tx instead of the base connection,
+// so a write can be grouped into a caller's transaction.
+func (r *AuditRepository) WithTx(tx *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: tx}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+type AuditLogFilter struct {
+	ActorID  *uuid.UUID
+	Action   string
+	TargetID *uuid.UUID
+
+	// Cursor selects keyset pagination; nil means "first page".
+	Cursor  *string
+	SortDir SortDir
+	Limit   int
+}
+
+type PaginatedAuditLogs struct {
+	Entries    []models.AuditLog
+	Total      int64
+	NextCursor *string
+}
+
+// List filters and paginates audit log entries newest-first by default,
+// using the same (created_at, id) keyset approach as UserRepository.List.
+func (r *AuditRepository) List(ctx context.Context, filter AuditLogFilter) (*PaginatedAuditLogs, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	desc := filter.SortDir != SortAsc
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if filter.Cursor != nil && *filter.Cursor != "" {
+		cur, err := decodeCursor(*filter.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor: %w", err)
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		query = query.Where(fmt.Sprintf("(created_at, id) %s (?, ?)", cmp), cur.SortValue, cur.ID)
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	var entries []models.AuditLog
+	err := query.
+		Order(fmt.Sprintf("created_at %s, id %s", dir, dir)).
+		Limit(limit + 1).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaginatedAuditLogs{Total: total}
+
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+	result.Entries = entries
+
+	if hasMore && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		next := encodeCursor(last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+		result.NextCursor = &next
+	}
+
+	return result, nil
+}
@@ -0,0 +1,94 @@
+// repository/email_job_repository.go - Durable send queue repository backing email.Queue
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// claimLease is how long a job claimed by ClaimBatch is held out of
+// circulation before it's eligible to be claimed again. It bounds how long
+// a job is stuck if the worker that claimed it crashes mid-delivery,
+// without requiring a SELECT ... FOR UPDATE SKIP LOCKED to avoid two
+// workers delivering the same job concurrently.
+const claimLease = 2 * time.Minute
+
+type EmailJobRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailJobRepository(db *gorm.DB) *EmailJobRepository {
+	return &EmailJobRepository{db: db}
+}
+
+func (r *EmailJobRepository) Create(ctx context.Context, job *models.EmailJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// ClaimBatch returns up to limit jobs due for delivery - not yet sent or
+// dead-lettered, with next_attempt_at at or before now - oldest first, and
+// leases each of them by pushing next_attempt_at claimLease into the
+// future so the same poll interval on another worker goroutine can't claim
+// them again before delivery has had a chance to finish.
+func (r *EmailJobRepository) ClaimBatch(ctx context.Context, now time.Time, limit int) ([]models.EmailJob, error) {
+	var batch []models.EmailJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Where("sent_at IS NULL AND dead_lettered_at IS NULL AND next_attempt_at <= ?", now).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(batch))
+		for i, j := range batch {
+			ids[i] = j.ID
+		}
+		return tx.Model(&models.EmailJob{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(claimLease)).Error
+	})
+	return batch, err
+}
+
+func (r *EmailJobRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.EmailJob{}).
+		Where("id = ?", id).
+		Update("sent_at", &now).Error
+}
+
+// MarkRetry records a failed attempt and reschedules the job for
+// nextAttemptAt, which the caller computes with its own backoff policy.
+func (r *EmailJobRepository) MarkRetry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastErr string) error {
+	return r.db.WithContext(ctx).Model(&models.EmailJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastErr,
+			"attempts":        gorm.Expr("attempts + 1"),
+		}).Error
+}
+
+// MarkDeadLettered records that id exhausted its retries and will not be
+// attempted again.
+func (r *EmailJobRepository) MarkDeadLettered(ctx context.Context, id uuid.UUID, lastErr string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.EmailJob{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"dead_lettered_at": &now,
+			"last_error":       lastErr,
+			"attempts":         gorm.Expr("attempts + 1"),
+		}).Error
+}
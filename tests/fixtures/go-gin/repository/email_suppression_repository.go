@@ -0,0 +1,37 @@
+// repository/email_suppression_repository.go - Bounce/complaint suppression list
+
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type EmailSuppressionRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailSuppressionRepository(db *gorm.DB) *EmailSuppressionRepository {
+	return &EmailSuppressionRepository{db: db}
+}
+
+// Suppress records email as undeliverable. Re-suppressing an address that's
+// already on the list (e.g. a second bounce) just updates the reason.
+func (r *EmailSuppressionRepository) Suppress(ctx context.Context, email string, reason models.SuppressionReason) error {
+	return r.db.WithContext(ctx).
+		Where("email = ?", email).
+		Assign(models.EmailSuppression{Email: email, Reason: reason}).
+		FirstOrCreate(&models.EmailSuppression{}).Error
+}
+
+// IsSuppressed reports whether email is on the suppression list.
+func (r *EmailSuppressionRepository) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.EmailSuppression{}).
+		Where("email = ?", email).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,60 @@
+// repository/outbox_repository.go - Durable event outbox repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// WithTx returns a repository bound to tx instead of the base connection,
+// so an event write can be grouped into the caller's transaction - see
+// AuditRepository.WithTx.
+func (r *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: tx}
+}
+
+func (r *OutboxRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ClaimBatch returns up to limit unprocessed events, oldest first, for
+// events.Dispatcher to publish.
+func (r *OutboxRepository) ClaimBatch(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var batch []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("processed_at IS NULL").
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&batch).Error
+	return batch, err
+}
+
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Update("processed_at", &now).Error
+}
+
+// IncrementAttempts is called when a claimed event fails to publish
+// cleanly, so a poll that keeps failing the same event is visible rather
+// than retried silently forever.
+func (r *OutboxRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
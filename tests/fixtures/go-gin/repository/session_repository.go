@@ -0,0 +1,71 @@
+// repository/session_repository.go - Refresh session repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *models.RefreshSession) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *SessionRepository) GetByJTI(ctx context.Context, jti string) (*models.RefreshSession, error) {
+	var session models.RefreshSession
+	err := r.db.WithContext(ctx).Where("jti = ?", jti).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate marks oldJTI as replaced by newJTI, the record of which refresh
+// token superseded it, so reuse of a stale, already-rotated token can be
+// detected and treated as a signal of token theft.
+func (r *SessionRepository) Rotate(ctx context.Context, oldJTI, newJTI string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshSession{}).
+		Where("jti = ?", oldJTI).
+		Updates(map[string]interface{}{
+			"replaced_by": newJTI,
+		}).Error
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, jti string) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshSession{}).
+		Where("jti = ?", jti).
+		Update("revoked_at", now).Error
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.RefreshSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+func (r *SessionRepository) ListActiveForUser(ctx context.Context, userID uuid.UUID) ([]models.RefreshSession, error) {
+	var sessions []models.RefreshSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("issued_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
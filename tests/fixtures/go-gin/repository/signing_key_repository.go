@@ -0,0 +1,42 @@
+// repository/signing_key_repository.go - Persistence for middleware.SigningKeySet
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type SigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+func (r *SigningKeyRepository) Create(ctx context.Context, key *models.SigningKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// ListLive returns every key that hasn't yet retired as of now, newest
+// first, so a SigningKeySet can recover its in-memory state - including
+// which key was active - after a restart.
+func (r *SigningKeyRepository) ListLive(ctx context.Context, now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.WithContext(ctx).
+		Where("retire_at > ?", now).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// DeleteRetired removes keys whose retirement has passed, so the table
+// doesn't grow unbounded across rotations.
+func (r *SigningKeyRepository) DeleteRetired(ctx context.Context, now time.Time) error {
+	return r.db.WithContext(ctx).Where("retire_at <= ?", now).Delete(&models.SigningKey{}).Error
+}
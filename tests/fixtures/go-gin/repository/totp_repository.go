@@ -0,0 +1,95 @@
+// repository/totp_repository.go - TOTP enrollment and recovery code repository
+
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+type TOTPRepository struct {
+	db *gorm.DB
+}
+
+func NewTOTPRepository(db *gorm.DB) *TOTPRepository {
+	return &TOTPRepository{db: db}
+}
+
+func (r *TOTPRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&totp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// Upsert replaces any existing enrollment for the user - re-enrolling
+// discards the previous secret and recovery codes.
+func (r *TOTPRepository) Upsert(ctx context.Context, totp *models.UserTOTP) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", totp.UserID).Delete(&models.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(totp).Error
+	})
+}
+
+func (r *TOTPRepository) Confirm(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.UserTOTP{}).
+		Where("user_id = ?", userID).
+		Update("confirmed_at", now).Error
+}
+
+// UpdateLastUsedStep records step as the most recently accepted TOTP code's
+// time-step, so a later replay of that same code within its skew window is
+// rejected - see models.UserTOTP.LastUsedStep.
+func (r *TOTPRepository) UpdateLastUsedStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	return r.db.WithContext(ctx).
+		Model(&models.UserTOTP{}).
+		Where("user_id = ?", userID).
+		Update("last_used_step", step).Error
+}
+
+func (r *TOTPRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+}
+
+func (r *TOTPRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.TOTPRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.TOTPRecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = models.TOTPRecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *TOTPRepository) GetUnusedRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]models.TOTPRecoveryCode, error) {
+	var codes []models.TOTPRecoveryCode
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Find(&codes).Error
+	return codes, err
+}
+
+func (r *TOTPRepository) MarkRecoveryCodeUsed(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&models.TOTPRecoveryCode{}).
+		Where("id = ?", id).
+		Update("used_at", now).Error
+}
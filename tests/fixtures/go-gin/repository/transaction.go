@@ -0,0 +1,25 @@
+// repository/transaction.go - Cross-repository transaction helper
+
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor groups writes across multiple repositories into one database
+// transaction, e.g. a user mutation and its audit log entry. Repositories
+// taking part must be rebound to the transaction via their WithTx method.
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Execute runs fn inside a transaction, rolling back if fn returns an error.
+func (t *Transactor) Execute(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return t.db.WithContext(ctx).Transaction(fn)
+}
@@ -0,0 +1,137 @@
+// repository/user_erasure.go - GDPR right-to-erasure anonymization
+
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// AnonymizeOptions carries the compliance metadata recorded alongside an
+// erasure - who asked for it and why - so the audit trail stands on its own
+// without needing to cross-reference a support ticket.
+type AnonymizeOptions struct {
+	ActorID *uuid.UUID
+	Reason  string
+}
+
+// Anonymize scrubs a user's PII in place rather than deleting the row, so
+// foreign keys (audit log, sessions, addresses aside) stay valid: email and
+// username are replaced with opaque placeholders, LastLoginIP and the
+// profile's name/phone/avatar/bio/date of birth are cleared, and every
+// UserAddress is deleted outright. It runs in a single transaction and
+// appends an AuditLog entry containing a SHA-256 of the pre-anonymization
+// user record, so a compliance request can be proven to have been honored
+// without retaining the PII it erased.
+//
+// Because email/username are overwritten before the row is touched again,
+// GetByEmail/GetByUsername naturally stop matching the old identifiers -
+// and, since neither query uses Unscoped(), a soft-deleted user's old
+// identifiers were already unreachable through them before this existed.
+func (r *UserRepository) Anonymize(ctx context.Context, id uuid.UUID, opts AnonymizeOptions) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Preload("Profile").First(&user, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		preImage, err := json.Marshal(user)
+		if err != nil {
+			return fmt.Errorf("marshaling pre-anonymization snapshot: %w", err)
+		}
+		digest := sha256.Sum256(preImage)
+
+		now := time.Now()
+		anonEmail := fmt.Sprintf("deleted-%s@invalid", uuid.New())
+		anonUsername := fmt.Sprintf("deleted_%s", shortID(id))
+
+		if err := tx.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"email":         anonEmail,
+			"username":      anonUsername,
+			"last_login_ip": "",
+			"anonymized_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if user.Profile != nil {
+			if err := tx.Model(&models.UserProfile{}).Where("user_id = ?", id).Updates(map[string]interface{}{
+				"first_name":    "",
+				"last_name":     "",
+				"phone":         "",
+				"avatar":        "",
+				"bio":           "",
+				"date_of_birth": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&models.UserAddress{}).Error; err != nil {
+			return err
+		}
+
+		metadata, _ := json.Marshal(map[string]string{
+			"reason":           opts.Reason,
+			"pre_image_sha256": hex.EncodeToString(digest[:]),
+		})
+
+		return tx.Create(&models.AuditLog{
+			ActorID:  opts.ActorID,
+			Action:   models.AuditActionAnonymize,
+			TargetID: &id,
+			Metadata: string(metadata),
+		}).Error
+	})
+}
+
+// shortID returns a short, non-cryptographic label derived from id for
+// display purposes (e.g. the anonymized username) - not a substitute for
+// the real uuid used in AnonymizeOptions/AuditLog.TargetID.
+func shortID(id uuid.UUID) string {
+	return strings.ReplaceAll(id.String(), "-", "")[:8]
+}
+
+// BulkAnonymize anonymizes each user independently, in its own transaction,
+// so one bad ID in a large batch doesn't roll back the rest. It returns the
+// IDs that were successfully anonymized before stopping at the first error.
+func (r *UserRepository) BulkAnonymize(ctx context.Context, ids []uuid.UUID, opts AnonymizeOptions) ([]uuid.UUID, error) {
+	anonymized := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if err := r.Anonymize(ctx, id, opts); err != nil {
+			return anonymized, fmt.Errorf("anonymizing %s: %w", id, err)
+		}
+		anonymized = append(anonymized, id)
+	}
+	return anonymized, nil
+}
+
+// PurgeInactiveUsers anonymizes every user who hasn't logged in within
+// olderThan, for a scheduled GDPR erasure job. It composes GetInactiveUsers
+// with BulkAnonymize and returns how many were purged before stopping at
+// the first error.
+func (r *UserRepository) PurgeInactiveUsers(ctx context.Context, olderThan time.Duration) (int64, error) {
+	days := int(olderThan.Hours() / 24)
+	inactive, err := r.GetInactiveUsers(ctx, days)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]uuid.UUID, len(inactive))
+	for i, u := range inactive {
+		ids[i] = u.ID
+	}
+
+	purged, err := r.BulkAnonymize(ctx, ids, AnonymizeOptions{Reason: "inactive_purge"})
+	return int64(len(purged)), err
+}
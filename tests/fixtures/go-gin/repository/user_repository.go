@@ -4,6 +4,12 @@ package repository
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,12 +18,27 @@ import (
 	"myapp/models"
 )
 
+// ErrInvalidCursor is returned by List when opts.Cursor doesn't decode, or
+// fails its HMAC check - either a malformed client request or a forged/
+// tampered value. The service layer maps it to a 400.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
 type UserRepository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	cursorSecret []byte
+}
+
+// NewUserRepository wires up the repository. cursorSecret signs cursors
+// returned by List's keyset mode (HMAC-SHA256) so a client can't forge an
+// arbitrary (sort_value, id) position - pass AuthConfig.JWTSecret.
+func NewUserRepository(db *gorm.DB, cursorSecret string) *UserRepository {
+	return &UserRepository{db: db, cursorSecret: []byte(cursorSecret)}
 }
 
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
+// WithTx returns a repository bound to tx instead of the base connection,
+// so a write can be grouped into a caller's transaction.
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{db: tx, cursorSecret: r.cursorSecret}
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
@@ -29,6 +50,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	err := r.db.WithContext(ctx).
 		Preload("Profile").
 		Preload("Preferences").
+		Preload("TOTP").
 		First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -67,40 +89,253 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id).Error
 }
 
-// Q: How can we implement efficient cursor-based pagination for large datasets?
-func (r *UserRepository) List(ctx context.Context, opts ListOptions) ([]models.User, int64, error) {
-	var users []models.User
-	var total int64
-
+// List supports two pagination modes, selected by which of opts.Cursor /
+// opts.Page is set: offset-based (opts.Page, for backwards compatibility)
+// and keyset/cursor-based (opts.Cursor, which scales to large tables since
+// it avoids OFFSET's need to walk and discard every preceding row).
+func (r *UserRepository) List(ctx context.Context, opts ListOptions) (*PaginatedResult, error) {
 	query := r.db.WithContext(ctx).Model(&models.User{})
 
-	// Apply filters
+	query = applyUserFilters(query, opts)
+
+	// Cursor mode skips COUNT(*): it's an O(N) scan on large tables, and a
+	// keyset caller only needs HasMore/NextCursor, not a total.
+	if opts.Cursor != nil {
+		return r.listByCursor(query, opts)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return r.listByPage(query, opts, total)
+}
+
+// applyUserFilters applies every ListOptions filter field that's set. The
+// admin search filters (CreatedAfter/CreatedBefore/EmailVerified/
+// LastLoginAfter/Roles) are additive with the legacy Role/Status/Search
+// fields so both the simple and admin-search callers share one code path.
+// Search uses ILIKE (case-insensitive) across email, username, and profile
+// name - a trigram GIN index on these columns is recommended once this
+// table grows past a few hundred thousand rows:
+//
+//	CREATE INDEX idx_users_search_trgm ON users USING gin (email gin_trgm_ops, username gin_trgm_ops);
+//	CREATE INDEX idx_user_profiles_search_trgm ON user_profiles USING gin (first_name gin_trgm_ops, last_name gin_trgm_ops);
+func applyUserFilters(query *gorm.DB, opts ListOptions) *gorm.DB {
 	if opts.Role != "" {
 		query = query.Where("role = ?", opts.Role)
 	}
+	if len(opts.Roles) > 0 {
+		query = query.Where("role IN ?", opts.Roles)
+	}
 	if opts.Status != "" {
 		query = query.Where("status = ?", opts.Status)
 	}
+	if opts.EmailVerified != nil {
+		query = query.Where("email_verified = ?", *opts.EmailVerified)
+	}
+	if opts.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *opts.CreatedAfter)
+	}
+	if opts.CreatedBefore != nil {
+		query = query.Where("created_at <= ?", *opts.CreatedBefore)
+	}
+	if opts.LastLoginAfter != nil {
+		query = query.Where("last_login_at >= ?", *opts.LastLoginAfter)
+	}
 	if opts.Search != "" {
 		search := "%" + opts.Search + "%"
-		query = query.Where("email LIKE ? OR username LIKE ?", search, search)
+		query = query.
+			Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id").
+			Where("users.email ILIKE ? OR users.username ILIKE ? OR user_profiles.first_name ILIKE ? OR user_profiles.last_name ILIKE ?",
+				search, search, search, search)
 	}
+	return query
+}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+func (r *UserRepository) listByPage(query *gorm.DB, opts ListOptions, total int64) (*PaginatedResult, error) {
+	order := "created_at DESC"
+	if opts.SortBy != "" {
+		desc := opts.SortDir != SortAsc
+		dir := "ASC"
+		if desc {
+			dir = "DESC"
+		}
+		if opts.SortBy == SortByName {
+			if opts.Search == "" {
+				// Search already left-joins user_profiles; avoid adding it twice.
+				query = query.Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id")
+			}
+			order = fmt.Sprintf("user_profiles.first_name %s, user_profiles.last_name %s", dir, dir)
+		} else {
+			order = fmt.Sprintf("%s %s", sortColumn(opts.SortBy), dir)
+		}
 	}
 
-	// Apply pagination
 	offset := (opts.Page - 1) * opts.PageSize
+	var users []models.User
 	err := query.
 		Preload("Profile").
 		Offset(offset).
 		Limit(opts.PageSize).
-		Order("created_at DESC").
+		Order(order).
 		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResult{Users: users, Total: total}, nil
+}
+
+// listByCursor fetches one row past the page limit to detect whether a
+// next page exists, and builds a compound WHERE clause on (sortCol, id) so
+// pagination stays stable even when sortCol has duplicate values. A
+// compound index on (sortCol, id) is recommended to keep this efficient.
+// It does not compute Total - see List's comment on why cursor mode skips
+// COUNT(*).
+func (r *UserRepository) listByCursor(query *gorm.DB, opts ListOptions) (*PaginatedResult, error) {
+	sortCol := sortColumn(opts.SortBy)
+	desc := opts.SortDir != SortAsc
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = opts.PageSize
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if *opts.Cursor != "" {
+		cur, err := decodeCursor(r.cursorSecret, *opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortCol, cmp), cur.SortValue, cur.ID)
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	var users []models.User
+	err := query.
+		Preload("Profile").
+		Order(fmt.Sprintf("%s %s, id %s", sortCol, dir, dir)).
+		Limit(limit + 1).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PaginatedResult{}
+
+	hasMore := len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
+	result.Users = users
+
+	if hasMore && len(users) > 0 {
+		last := users[len(users)-1]
+		next := encodeCursor(r.cursorSecret, sortValue(last, opts.SortBy), last.ID)
+		result.NextCursor = &next
+	}
+	if *opts.Cursor != "" && len(users) > 0 {
+		first := users[0]
+		prev := encodeCursor(r.cursorSecret, sortValue(first, opts.SortBy), first.ID)
+		result.PrevCursor = &prev
+	}
+
+	return result, nil
+}
+
+func sortColumn(sortBy SortField) string {
+	switch sortBy {
+	case SortByID:
+		return "id"
+	case SortByEmail:
+		return "email"
+	case SortByLastLogin:
+		return "last_login_at"
+	default:
+		return "created_at"
+	}
+}
+
+func sortValue(u models.User, sortBy SortField) string {
+	switch sortBy {
+	case SortByID:
+		return u.ID.String()
+	case SortByEmail:
+		return u.Email
+	case SortByLastLogin:
+		if u.LastLoginAt == nil {
+			return ""
+		}
+		return u.LastLoginAt.Format(time.RFC3339Nano)
+	default:
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
 
-	return users, total, err
+type cursorPayload struct {
+	SortValue string    `json:"sort_value"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// signedCursor is what actually gets base64-encoded: the payload plus an
+// HMAC-SHA256 MAC over it, keyed by cursorSecret, so a client can read a
+// cursor's position but can't forge or tweak one (e.g. to page past a
+// filter they're not supposed to see) without the server's secret.
+type signedCursor struct {
+	Payload cursorPayload `json:"payload"`
+	MAC     string        `json:"mac"`
+}
+
+func encodeCursor(secret []byte, sortValue string, id uuid.UUID) string {
+	payload := cursorPayload{SortValue: sortValue, ID: id}
+	data, _ := json.Marshal(payload)
+
+	signed, _ := json.Marshal(signedCursor{
+		Payload: payload,
+		MAC:     base64.URLEncoding.EncodeToString(cursorMAC(secret, data)),
+	})
+	return base64.URLEncoding.EncodeToString(signed)
+}
+
+func decodeCursor(secret []byte, cursor string) (*cursorPayload, error) {
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var sc signedCursor
+	if err := json.Unmarshal(raw, &sc); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	wantMAC, err := base64.URLEncoding.DecodeString(sc.MAC)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	data, _ := json.Marshal(sc.Payload)
+	if !hmac.Equal(wantMAC, cursorMAC(secret, data)) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &sc.Payload, nil
+}
+
+func cursorMAC(secret, data []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	return mac.Sum(nil)
 }
 
 func (r *UserRepository) Search(ctx context.Context, query string, limit int) ([]models.User, error) {
@@ -166,6 +401,70 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID,
 		}).Error
 }
 
+// UpdateFields applies a partial column update to the user row, analogous
+// to UpdateLastLogin but for an arbitrary caller-supplied field set - used
+// by UserService.UpdateUser so an unset Option[T] field is simply absent
+// from the map rather than needing its own single-column method.
+func (r *UserRepository) UpdateFields(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// UpsertProfileFields applies a partial column update to userID's profile,
+// creating the row first if it doesn't exist yet - a registration that
+// skipped profile fields entirely shouldn't block a later profile edit.
+func (r *UserRepository) UpsertProfileFields(ctx context.Context, userID uuid.UUID, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Assign(updates).
+		FirstOrCreate(&models.UserProfile{UserID: userID}).Error
+}
+
+// DeleteUnverifiedOlderThan soft-deletes every StatusPending, unverified
+// user created before cutoff, for maintenance.CleanupUnverifiedUsers.
+func (r *UserRepository) DeleteUnverifiedOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND email_verified = ? AND created_at < ?", models.StatusPending, false, cutoff).
+		Delete(&models.User{})
+
+	return result.RowsAffected, result.Error
+}
+
+// ExpireSuspensions reactivates every suspended user whose SuspendedUntil
+// has passed as of now, for maintenance.ExpireSuspensions.
+func (r *UserRepository) ExpireSuspensions(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("status = ? AND suspended_until IS NOT NULL AND suspended_until <= ?", models.StatusSuspended, now).
+		Updates(map[string]interface{}{
+			"status":          models.StatusActive,
+			"suspended_until": nil,
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// PurgeSoftDeleted hard-deletes user rows whose soft-delete (via Delete)
+// happened before cutoff, for maintenance.PurgeSoftDeleted. Unscoped is
+// required here since the normal query scope already excludes soft-deleted
+// rows from everything, including a plain Delete.
+func (r *UserRepository) PurgeSoftDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Delete(&models.User{})
+
+	return result.RowsAffected, result.Error
+}
+
 func (r *UserRepository) BulkUpdateStatus(ctx context.Context, userIDs []uuid.UUID, status models.UserStatus) (int64, error) {
 	result := r.db.WithContext(ctx).
 		Model(&models.User{}).
@@ -191,6 +490,9 @@ func (r *UserRepository) GetStats(ctx context.Context) (*UserStats, error) {
 	monthAgo := time.Now().AddDate(0, -1, 0)
 	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", monthAgo).Count(&stats.NewThisMonth)
 
+	// Anonymized via the GDPR erasure pipeline
+	r.db.WithContext(ctx).Model(&models.User{}).Where("anonymized_at IS NOT NULL").Count(&stats.Anonymized)
+
 	// By role
 	stats.ByRole = make(map[string]int64)
 	var roleStats []struct {
@@ -205,12 +507,57 @@ func (r *UserRepository) GetStats(ctx context.Context) (*UserStats, error) {
 	return &stats, nil
 }
 
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByID        SortField = "id"
+	SortByEmail     SortField = "email"
+	SortByLastLogin SortField = "last_login"
+
+	// SortByName requires a join to user_profiles and so is only honored in
+	// page mode; cursor mode falls back to SortByCreatedAt, since joined
+	// columns don't fit the single-table (sortCol, id) keyset predicate.
+	SortByName SortField = "name"
+)
+
+type SortDir string
+
+const (
+	SortAsc  SortDir = "asc"
+	SortDesc SortDir = "desc"
+)
+
 type ListOptions struct {
 	Page     int
 	PageSize int
 	Role     string
 	Status   string
 	Search   string
+
+	// Cursor selects keyset pagination mode when non-nil; an empty string
+	// cursor means "first page". Limit only applies in this mode; SortBy/
+	// SortDir apply to both modes.
+	Cursor  *string
+	SortBy  SortField
+	SortDir SortDir
+	Limit   int
+
+	// Admin search filters - all optional and additive with Role/Status/Search.
+	Roles          []string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	EmailVerified  *bool
+	LastLoginAfter *time.Time
+}
+
+// PaginatedResult is returned by UserRepository.List for both pagination
+// modes. NextCursor/PrevCursor are only populated in cursor mode.
+type PaginatedResult struct {
+	Users      []models.User
+	Total      int64
+	NextCursor *string
+	PrevCursor *string
 }
 
 type UserStats struct {
@@ -218,5 +565,6 @@ type UserStats struct {
 	Active       int64
 	Verified     int64
 	NewThisMonth int64
+	Anonymized   int64
 	ByRole       map[string]int64
 }
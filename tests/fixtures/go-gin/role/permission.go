@@ -0,0 +1,20 @@
+// role/permission.go - Fine-grained permission constants
+
+package role
+
+// Permission is a single grantable capability, written "resource:action" so
+// new ones stay easy to scan and grep for across handlers.
+type Permission string
+
+const (
+	PermUsersRead   Permission = "users:read"
+	PermUsersWrite  Permission = "users:write"
+	PermUsersDelete Permission = "users:delete"
+	PermUsersStats  Permission = "users:stats"
+
+	// PermSelfUpdate is granted to every signed-in role by default; it only
+	// does anything in combination with a resource-scoped PermissionChecker
+	// like middleware.SelfParamChecker, which additionally requires the
+	// caller to own the resource being acted on.
+	PermSelfUpdate Permission = "self:update"
+)
@@ -0,0 +1,77 @@
+// role/role.go - Role to permission mapping
+
+package role
+
+import (
+	"sync/atomic"
+
+	"myapp/models"
+)
+
+// defaultPermissions is used until Load replaces it with an
+// operator-supplied mapping, so the app is usable out of the box.
+var defaultPermissions = map[models.UserRole][]Permission{
+	models.RoleAdmin:     {PermUsersRead, PermUsersWrite, PermUsersDelete, PermUsersStats, PermSelfUpdate},
+	models.RoleModerator: {PermUsersRead, PermUsersWrite, PermSelfUpdate},
+	models.RoleUser:      {PermSelfUpdate},
+	models.RoleGuest:     {},
+}
+
+// permissions is held behind an atomic pointer (rather than a bare global)
+// since main.go's config hot-reload calls Load/LoadConfig from the config
+// watcher goroutine while request goroutines read it concurrently through
+// Expand/Has - same swap-the-whole-value approach as config.Store.
+var permissions atomic.Pointer[map[models.UserRole][]Permission]
+
+func init() {
+	permissions.Store(&defaultPermissions)
+}
+
+// Load replaces the role->permission mapping wholesale, e.g. from
+// config.Config.RBAC.Roles, so operators can define custom roles without
+// recompiling. A nil or empty mapping leaves the currently installed
+// mapping (the defaults, until a non-empty one is loaded) in place.
+func Load(mapping map[models.UserRole][]Permission) {
+	if len(mapping) == 0 {
+		return
+	}
+	permissions.Store(&mapping)
+}
+
+// LoadConfig converts a raw role-name -> permission-string mapping (as
+// parsed from config.RBACConfig.Roles) and installs it via Load.
+func LoadConfig(raw map[string][]string) {
+	if len(raw) == 0 {
+		return
+	}
+	mapping := make(map[models.UserRole][]Permission, len(raw))
+	for roleName, perms := range raw {
+		converted := make([]Permission, len(perms))
+		for i, p := range perms {
+			converted[i] = Permission(p)
+		}
+		mapping[models.UserRole(roleName)] = converted
+	}
+	Load(mapping)
+}
+
+// Expand returns the set of permissions granted to r.
+func Expand(r models.UserRole) map[Permission]bool {
+	granted := (*permissions.Load())[r]
+	set := make(map[Permission]bool, len(granted))
+	for _, p := range granted {
+		set[p] = true
+	}
+	return set
+}
+
+// Has reports whether r carries every permission in required.
+func Has(r models.UserRole, required ...Permission) bool {
+	granted := Expand(r)
+	for _, perm := range required {
+		if !granted[perm] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+// service/email_event_subscriber.go - Wires EmailService into the event bus
+
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+
+	"myapp/events"
+	"myapp/models"
+	"myapp/repository"
+)
+
+// EmailEventSubscriber adapts EmailService to events.Handler, so
+// UserService no longer calls it directly - see events.Bus and
+// events.Dispatcher for how these handlers end up invoked. Each handler
+// refetches the user by ID since an outbox event only carries the scalar
+// fields its producer recorded, not the full row.
+type EmailEventSubscriber struct {
+	userRepo     *repository.UserRepository
+	emailService *EmailService
+}
+
+func NewEmailEventSubscriber(userRepo *repository.UserRepository, emailService *EmailService) *EmailEventSubscriber {
+	return &EmailEventSubscriber{userRepo: userRepo, emailService: emailService}
+}
+
+// Register subscribes every handler this subscriber owns onto bus.
+func (s *EmailEventSubscriber) Register(bus *events.Bus) {
+	bus.Subscribe(events.TypeUserCreated, s.onUserCreated)
+	bus.Subscribe(events.TypeUserSuspended, s.onUserSuspended)
+	bus.Subscribe(events.TypeTOTPSecurityAlert, s.onTOTPSecurityAlert)
+}
+
+func (s *EmailEventSubscriber) onUserCreated(ctx context.Context, event events.Event) {
+	var payload events.UserCreated
+	if err := event.(events.RawEvent).Decode(&payload); err != nil {
+		log.Printf("email subscriber: decoding %s: %v", event.Type(), err)
+		return
+	}
+	if !payload.SendVerification {
+		return
+	}
+
+	user, err := s.loadUser(ctx, payload.UserID, event.Type())
+	if err != nil {
+		return
+	}
+	if err := s.emailService.SendVerificationEmail(user); err != nil {
+		log.Printf("email subscriber: sending verification email to user %s: %v", payload.UserID, err)
+	}
+}
+
+func (s *EmailEventSubscriber) onUserSuspended(ctx context.Context, event events.Event) {
+	var payload events.UserSuspended
+	if err := event.(events.RawEvent).Decode(&payload); err != nil {
+		log.Printf("email subscriber: decoding %s: %v", event.Type(), err)
+		return
+	}
+
+	user, err := s.loadUser(ctx, payload.UserID, event.Type())
+	if err != nil {
+		return
+	}
+	if err := s.emailService.SendSuspensionNotice(user, payload.Reason, payload.DurationDays); err != nil {
+		log.Printf("email subscriber: sending suspension notice to user %s: %v", payload.UserID, err)
+	}
+}
+
+func (s *EmailEventSubscriber) onTOTPSecurityAlert(ctx context.Context, event events.Event) {
+	// UserService publishes this event straight onto the bus rather than
+	// through the outbox, so it can arrive as the typed struct itself.
+	var payload events.TOTPSecurityAlert
+	switch e := event.(type) {
+	case events.TOTPSecurityAlert:
+		payload = e
+	case events.RawEvent:
+		if err := e.Decode(&payload); err != nil {
+			log.Printf("email subscriber: decoding %s: %v", event.Type(), err)
+			return
+		}
+	default:
+		log.Printf("email subscriber: unexpected %T for %s", event, event.Type())
+		return
+	}
+
+	user, err := s.loadUser(ctx, payload.UserID, event.Type())
+	if err != nil {
+		return
+	}
+	if err := s.emailService.SendNotificationEmail(user, "security_alert", map[string]interface{}{
+		"Action": payload.Action,
+	}); err != nil {
+		log.Printf("email subscriber: sending security alert to user %s: %v", payload.UserID, err)
+	}
+}
+
+func (s *EmailEventSubscriber) loadUser(ctx context.Context, rawUserID, eventType string) (*models.User, error) {
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		log.Printf("email subscriber: invalid user id in %s: %v", eventType, err)
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Printf("email subscriber: fetching user %s for %s: %v", rawUserID, eventType, err)
+		return nil, err
+	}
+	return user, nil
+}
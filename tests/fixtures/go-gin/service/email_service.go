@@ -3,27 +3,88 @@
 package service
 
 import (
+	"context"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
 	"fmt"
 	"html/template"
+	"log"
 	"strings"
 
 	"myapp/config"
+	"myapp/email"
 	"myapp/models"
+	"myapp/repository"
 )
 
+//go:embed templates/*.html
+var templateFS embed.FS
+
 type EmailService struct {
-	config   *config.Config
-	fromEmail string
-	siteName  string
-	baseURL   string
+	config          *config.Config
+	fromEmail       string
+	siteName        string
+	baseURL         string
+	queue           *email.Queue
+	suppressionRepo *repository.EmailSuppressionRepository
 }
 
-func NewEmailService(cfg *config.Config) *EmailService {
+func NewEmailService(cfg *config.Config, suppressionRepo *repository.EmailSuppressionRepository, jobRepo *repository.EmailJobRepository) *EmailService {
+	transport := &email.SMTPTransport{
+		Host:     cfg.Email.SMTPHost,
+		Port:     cfg.Email.SMTPPort,
+		Username: cfg.Email.Username,
+		Password: cfg.Email.Password,
+		UseTLS:   cfg.Email.UseTLS,
+		Signer:   buildDKIMSigner(cfg.Email),
+	}
+
+	queue := email.NewQueue(jobRepo, transport, cfg.Email.QueueWorkers, cfg.Email.QueueMaxAttempts, cfg.Email.QueueBaseBackoff, cfg.Email.QueuePollInterval)
+	queue.OnDeadLetter = func(msg *email.Message, err error) {
+		log.Printf("email: permanently failed to deliver to %s: %v", msg.To, err)
+	}
+
 	return &EmailService{
-		config:    cfg,
-		fromEmail: cfg.Email.FromAddress,
-		siteName:  cfg.App.Name,
-		baseURL:   cfg.App.BaseURL,
+		config:          cfg,
+		fromEmail:       cfg.Email.FromAddress,
+		siteName:        cfg.App.Name,
+		baseURL:         cfg.App.BaseURL,
+		queue:           queue,
+		suppressionRepo: suppressionRepo,
+	}
+}
+
+// Start runs the email queue's poller until ctx is done - see
+// email.Queue.Start. Call it from a background goroutine, mirroring how
+// events.Dispatcher.Start is launched in main.go.
+func (s *EmailService) Start(ctx context.Context) {
+	s.queue.Start(ctx)
+}
+
+// buildDKIMSigner returns nil when no private key is configured, so
+// SMTPTransport sends unsigned mail - the common case for local/dev relays.
+func buildDKIMSigner(cfg config.EmailConfig) *email.DKIMSigner {
+	if cfg.DKIMPrivateKeyPEM == "" {
+		return nil
+	}
+
+	block, _ := pem.Decode([]byte(cfg.DKIMPrivateKeyPEM))
+	if block == nil {
+		log.Printf("email: DKIM_PRIVATE_KEY_PEM is not valid PEM, sending unsigned")
+		return nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		log.Printf("email: failed to parse DKIM private key, sending unsigned: %v", err)
+		return nil
+	}
+
+	return &email.DKIMSigner{
+		Domain:     cfg.DKIMDomain,
+		Selector:   cfg.DKIMSelector,
+		PrivateKey: key,
 	}
 }
 
@@ -33,9 +94,9 @@ func (s *EmailService) SendVerificationEmail(user *models.User) error {
 
 	subject := fmt.Sprintf("Verify your email for %s", s.siteName)
 	body := s.renderTemplate("verify_email", map[string]interface{}{
-		"User":           user,
+		"User":            user,
 		"VerificationURL": verificationURL,
-		"SiteName":       s.siteName,
+		"SiteName":        s.siteName,
 	})
 
 	return s.sendEmail(user.Email, subject, body)
@@ -79,7 +140,6 @@ func (s *EmailService) SendSuspensionNotice(user *models.User, reason string, du
 	return s.sendEmail(user.Email, subject, body)
 }
 
-// Q: What's the best approach for handling email delivery failures and retries in Go?
 func (s *EmailService) SendBulkEmail(users []*models.User, subject string, templateName string, extraData map[string]interface{}) (*BulkEmailResult, error) {
 	result := &BulkEmailResult{
 		Sent:   0,
@@ -145,19 +205,51 @@ func (s *EmailService) SendNotificationEmail(user *models.User, notificationType
 	return s.sendEmail(user.Email, subjects[notificationType], body)
 }
 
+// Suppress records that email bounced or complained, so future sends to it
+// are skipped rather than burning another delivery attempt against it.
+func (s *EmailService) Suppress(ctx context.Context, emailAddr, reason string) error {
+	return s.suppressionRepo.Suppress(ctx, emailAddr, models.SuppressionReason(reason))
+}
+
+// sendEmail enqueues the message for async delivery, after checking the
+// suppression list so a bounced or complained-about address is skipped
+// instead of being handed to the transport again.
 func (s *EmailService) sendEmail(to, subject, body string) error {
-	// Would implement actual email sending via SMTP or email service
-	// For now, just log
-	fmt.Printf("Sending email to %s: %s\n", to, subject)
+	suppressed, err := s.suppressionRepo.IsSuppressed(context.Background(), to)
+	if err != nil {
+		return fmt.Errorf("checking suppression list: %w", err)
+	}
+	if suppressed {
+		log.Printf("email: skipping suppressed address %s", to)
+		return nil
+	}
+
+	s.queue.Enqueue(&email.Message{
+		To:      to,
+		From:    s.fromEmail,
+		Subject: subject,
+		Body:    body,
+	})
 	return nil
 }
 
 func (s *EmailService) renderTemplate(name string, data map[string]interface{}) string {
-	// Would load and render actual templates
+	content, err := templateFS.ReadFile("templates/" + name + ".html")
+	if err != nil {
+		log.Printf("email: no template named %q: %v", name, err)
+		return ""
+	}
+
+	tmpl, err := parseTemplate(name, string(content))
+	if err != nil {
+		log.Printf("email: parsing template %q: %v", name, err)
+		return ""
+	}
+
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Template: %s\n", name))
-	for k, v := range data {
-		sb.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+	if err := tmpl.Execute(&sb, data); err != nil {
+		log.Printf("email: rendering template %q: %v", name, err)
+		return ""
 	}
 	return sb.String()
 }
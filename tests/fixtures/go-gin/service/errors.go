@@ -0,0 +1,88 @@
+// service/errors.go - Typed domain errors with HTTP status mapping
+
+package service
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable identifier safe to expose to API
+// clients and match on programmatically - unlike Message, it never changes
+// wording between releases.
+type ErrorCode string
+
+const (
+	CodeUserNotFound     ErrorCode = "user.not_found"
+	CodeEmailExists      ErrorCode = "user.email_exists"
+	CodeUsernameExists   ErrorCode = "user.username_exists"
+	CodeInvalidPassword  ErrorCode = "user.invalid_password"
+	CodeUserSuspended    ErrorCode = "user.suspended"
+	CodeUserNotVerified  ErrorCode = "user.not_verified"
+	CodeTOTPNotEnrolled  ErrorCode = "auth.totp_not_enrolled"
+	CodeTOTPAlreadySetUp ErrorCode = "auth.totp_already_enabled"
+	CodeInvalidTOTPCode  ErrorCode = "auth.invalid_totp_code"
+	CodeInvalidToken     ErrorCode = "auth.invalid_token"
+	CodeIdentityLinked   ErrorCode = "auth.identity_already_linked"
+	CodeLastAuthMethod   ErrorCode = "auth.last_auth_method"
+	CodeInvalidCursor    ErrorCode = "user.invalid_cursor"
+	CodePasswordTooWeak  ErrorCode = "user.password_too_weak"
+	CodePasswordPwned    ErrorCode = "user.password_pwned"
+	CodeForbiddenField   ErrorCode = "user.forbidden_field"
+)
+
+// Error is the error type every exported service method returns for
+// expected, caller-actionable failures. Handlers push it to gin via
+// c.Error(err) and let middleware.ErrorResponder render it; they should
+// not need to switch on sentinel values themselves.
+type Error struct {
+	Code    ErrorCode
+	Status  int
+	Message string
+	Details map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func NewError(code ErrorCode, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// WithDetails attaches field-level validation detail, e.g. {"email": "already registered"}.
+func (e *Error) WithDetails(details map[string]string) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+// IsNotFound reports whether err is a service.Error mapped to 404.
+func IsNotFound(err error) bool {
+	var svcErr *Error
+	return errors.As(err, &svcErr) && svcErr.Status == http.StatusNotFound
+}
+
+// IsConflict reports whether err is a service.Error mapped to 409.
+func IsConflict(err error) bool {
+	var svcErr *Error
+	return errors.As(err, &svcErr) && svcErr.Status == http.StatusConflict
+}
+
+var (
+	ErrUserNotFound     = NewError(CodeUserNotFound, http.StatusNotFound, "user not found")
+	ErrEmailExists      = NewError(CodeEmailExists, http.StatusConflict, "email already registered")
+	ErrUsernameExists   = NewError(CodeUsernameExists, http.StatusConflict, "username already taken")
+	ErrInvalidPassword  = NewError(CodeInvalidPassword, http.StatusUnauthorized, "invalid password")
+	ErrUserSuspended    = NewError(CodeUserSuspended, http.StatusForbidden, "user account is suspended")
+	ErrUserNotVerified  = NewError(CodeUserNotVerified, http.StatusForbidden, "email not verified")
+	ErrTOTPNotEnrolled  = NewError(CodeTOTPNotEnrolled, http.StatusBadRequest, "totp is not enrolled for this user")
+	ErrTOTPAlreadySetUp = NewError(CodeTOTPAlreadySetUp, http.StatusConflict, "totp is already enabled")
+	ErrInvalidTOTPCode  = NewError(CodeInvalidTOTPCode, http.StatusBadRequest, "invalid totp code")
+	ErrIdentityLinked   = NewError(CodeIdentityLinked, http.StatusConflict, "this provider account is already linked to another user")
+	ErrLastAuthMethod   = NewError(CodeLastAuthMethod, http.StatusConflict, "cannot unlink the only way to sign in to this account")
+	ErrInvalidCursor    = NewError(CodeInvalidCursor, http.StatusBadRequest, "invalid pagination cursor")
+	ErrPasswordTooWeak  = NewError(CodePasswordTooWeak, http.StatusBadRequest, "password does not meet the minimum security requirements")
+	ErrPasswordPwned    = NewError(CodePasswordPwned, http.StatusBadRequest, "password has appeared in a known data breach")
+	ErrForbiddenField   = NewError(CodeForbiddenField, http.StatusForbidden, "you do not have permission to change this field")
+)
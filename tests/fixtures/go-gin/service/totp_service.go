@@ -0,0 +1,225 @@
+// service/totp_service.go - TOTP two-factor authentication
+
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/crypto/bcrypt"
+
+	"myapp/events"
+	"myapp/models"
+	"myapp/utils"
+)
+
+const totpRecoveryCodeCount = 10
+
+func (s *UserService) totpEncryptionKey() ([]byte, error) {
+	secret := s.config.Auth.OTPEncryptionKey
+	if secret == "" {
+		secret = s.config.Auth.JWTSecret
+	}
+	return utils.DeriveEncryptionKey(secret, "totp-secret")
+}
+
+// EnrollTOTP generates and stores a new (unconfirmed) TOTP secret for the
+// user and returns the otpauth:// URI plus a rendered QR code, so the
+// caller never has to display the raw secret.
+func (s *UserService) EnrollTOTP(ctx context.Context, userID uuid.UUID, accountEmail string) (otpauthURL string, qrPNG []byte, err error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key, err := s.totpEncryptionKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	encrypted, err := utils.EncryptSecret(key, secret)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := s.totpRepo.Upsert(ctx, &models.UserTOTP{
+		UserID:          userID,
+		EncryptedSecret: encrypted,
+	}); err != nil {
+		return "", nil, err
+	}
+
+	otpauthURL = utils.BuildOTPAuthURL(s.config.App.Name, accountEmail, secret)
+	qrPNG, err = qrcode.Encode(otpauthURL, qrcode.Medium, 256)
+	if err != nil {
+		return "", nil, fmt.Errorf("rendering qr code: %w", err)
+	}
+
+	return otpauthURL, qrPNG, nil
+}
+
+// ConfirmTOTP activates 2FA once the user proves possession of the secret
+// by submitting one valid code, and issues ten recovery codes.
+func (s *UserService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	valid, err := s.verifyTOTPCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	if err := s.totpRepo.Confirm(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(totpRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(recoveryCodes))
+	for i, rc := range recoveryCodes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(rc), s.config.Auth.BCryptCost)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = string(hash)
+	}
+
+	if err := s.totpRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	s.bus.Publish(ctx, events.TOTPSecurityAlert{UserID: userID.String(), Action: "enabled"})
+
+	return recoveryCodes, nil
+}
+
+// RegenerateRecoveryCodes invalidates every existing recovery code and
+// issues a fresh set, gated on a valid current TOTP code so a stolen
+// recovery code alone can't be used to mint more of them.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	valid, err := s.verifyTOTPCode(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	recoveryCodes, err := utils.GenerateRecoveryCodes(totpRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(recoveryCodes))
+	for i, rc := range recoveryCodes {
+		hash, err := bcrypt.GenerateFromPassword([]byte(rc), s.config.Auth.BCryptCost)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = string(hash)
+	}
+
+	if err := s.totpRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// DisableTOTP requires a valid current code before turning 2FA off.
+func (s *UserService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	valid, err := s.verifyTOTPCode(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidTOTPCode
+	}
+	if err := s.totpRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	s.bus.Publish(ctx, events.TOTPSecurityAlert{UserID: userID.String(), Action: "disabled"})
+
+	return nil
+}
+
+// HasTOTPEnabled reports whether the user must complete a 2FA challenge at login.
+func (s *UserService) HasTOTPEnabled(ctx context.Context, userID uuid.UUID) bool {
+	totp, err := s.totpRepo.GetByUserID(ctx, userID)
+	return err == nil && totp.Enabled()
+}
+
+// VerifyTOTP checks a 6-digit code against the user's enrolled, confirmed secret.
+func (s *UserService) VerifyTOTP(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	totp, err := s.totpRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, ErrTOTPNotEnrolled
+	}
+	if !totp.Enabled() {
+		return false, ErrTOTPNotEnrolled
+	}
+	return s.verifyTOTPCode(ctx, userID, code)
+}
+
+// verifyTOTPCode checks code against the user's enrolled secret and, on a
+// match, rejects it if its time-step was already consumed - otherwise the
+// same code stays valid (and replayable) for its whole ±1-step skew window.
+// A step only counts as consumed once it's actually accepted here, so a
+// wrong code never advances the guard.
+func (s *UserService) verifyTOTPCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	totp, err := s.totpRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, ErrTOTPNotEnrolled
+	}
+
+	key, err := s.totpEncryptionKey()
+	if err != nil {
+		return false, err
+	}
+
+	secret, err := utils.DecryptSecret(key, totp.EncryptedSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTPCode(secret, code)
+	if !ok {
+		return false, nil
+	}
+	if step <= totp.LastUsedStep {
+		return false, nil
+	}
+
+	if err := s.totpRepo.UpdateLastUsedStep(ctx, userID, step); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ConsumeRecoveryCode redeems one of the user's unused recovery codes as a
+// fallback for a lost authenticator device. Each code can only be used once.
+func (s *UserService) ConsumeRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	codes, err := s.totpRepo.GetUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, rc := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(rc.CodeHash), []byte(code)) == nil {
+			if err := s.totpRepo.MarkRecoveryCodeUsed(ctx, rc.ID); err != nil {
+				return true, err
+			}
+			s.bus.Publish(ctx, events.TOTPSecurityAlert{UserID: userID.String(), Action: "recovery_code_used"})
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
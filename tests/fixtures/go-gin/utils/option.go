@@ -0,0 +1,48 @@
+// utils/option.go - Generic optional value for partial-update inputs
+
+package utils
+
+import "encoding/json"
+
+// Option is a small sql.Null-style optional: it distinguishes "the caller
+// didn't supply this field" from "the caller supplied its zero value" (e.g.
+// clearing Bio to "" is different from leaving it untouched). Unmarshaling
+// JSON only sets it when the corresponding key is present in the payload,
+// so a partial-update struct built from Option fields naturally ignores
+// whatever the client didn't send.
+type Option[T any] struct {
+	set   bool
+	value T
+}
+
+// Some returns a set Option holding v.
+func Some[T any](v T) Option[T] {
+	return Option[T]{set: true, value: v}
+}
+
+// IsSet reports whether the option was supplied.
+func (o Option[T]) IsSet() bool {
+	return o.set
+}
+
+// Value returns the wrapped value, or T's zero value if unset.
+func (o Option[T]) Value() T {
+	return o.value
+}
+
+func (o *Option[T]) UnmarshalJSON(data []byte) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.set = true
+	o.value = v
+	return nil
+}
+
+func (o Option[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
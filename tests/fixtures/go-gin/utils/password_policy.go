@@ -0,0 +1,225 @@
+// utils/password_policy.go - Centralized password rules plus an optional
+// online breach check, wrapped behind PasswordPolicy so the offline/online
+// switch and the breach lookup itself are both injectable instead of baked
+// into ValidatePassword.
+
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pwnedPasswordsRangeURL = "https://api.pwnedpasswords.com/range/%s"
+
+// PwnedChecker abstracts the breach-count lookup so it can be swapped out
+// entirely - a stub that never calls the network, say - rather than only
+// toggled on or off. pwnedRangeChecker is the only production implementation.
+type PwnedChecker interface {
+	CheckBreached(password string) (int, error)
+}
+
+// PasswordPolicy layers configurable rules - minimum length, an additional
+// deny-list, and a breach check - on top of the offline checks in
+// ValidatePassword/GetPasswordStrength.
+type PasswordPolicy struct {
+	// Pwned performs the online breach lookup. Left nil (e.g. in tests, or
+	// whenever BreachCheck is false) it's simply never called.
+	Pwned PwnedChecker
+
+	BreachCheck      bool
+	BreachCountLimit int
+	MinLength        int
+	DenyList         []string
+}
+
+// PasswordPolicyConfig configures a new PasswordPolicy. Zero values fall
+// back to the defaults documented on NewPasswordPolicy.
+type PasswordPolicyConfig struct {
+	MinLength          int
+	DenyList           []string
+	BreachCheckEnabled bool
+	BreachCheckTimeout time.Duration
+	BreachCountLimit   int
+}
+
+// NewPasswordPolicy builds a PasswordPolicy from config (AuthConfig's
+// Password* fields). MinLength defaults to 8, BreachCountLimit to 1 (any
+// appearance in the Pwned Passwords corpus counts as breached), and
+// BreachCheckTimeout to 3s.
+func NewPasswordPolicy(cfg PasswordPolicyConfig) *PasswordPolicy {
+	minLength := cfg.MinLength
+	if minLength <= 0 {
+		minLength = 8
+	}
+	limit := cfg.BreachCountLimit
+	if limit <= 0 {
+		limit = 1
+	}
+
+	policy := &PasswordPolicy{
+		BreachCheck:      cfg.BreachCheckEnabled,
+		BreachCountLimit: limit,
+		MinLength:        minLength,
+		DenyList:         cfg.DenyList,
+	}
+	if cfg.BreachCheckEnabled {
+		timeout := cfg.BreachCheckTimeout
+		if timeout <= 0 {
+			timeout = 3 * time.Second
+		}
+		policy.Pwned = &pwnedRangeChecker{httpClient: &http.Client{Timeout: timeout}}
+	}
+	return policy
+}
+
+// ValidateOffline runs every rule that doesn't require a network call:
+// ValidatePassword's baseline checks, this policy's minimum length and
+// deny-list, and - if any identifiers (username, email) are passed -
+// rejection of a password that contains one of them.
+func (p *PasswordPolicy) ValidateOffline(password string, identifiers ...string) []string {
+	errs := ValidatePassword(password)
+
+	if p.MinLength > 8 && len(password) < p.MinLength {
+		errs = append(errs, fmt.Sprintf("password must be at least %d characters", p.MinLength))
+	}
+
+	lowerPassword := strings.ToLower(password)
+	for _, denied := range p.DenyList {
+		if lowerPassword == strings.ToLower(denied) {
+			errs = append(errs, "this password is too common")
+			break
+		}
+	}
+
+	for _, id := range identifiers {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if strings.Contains(lowerPassword, strings.ToLower(id)) {
+			errs = append(errs, "password must not contain your username or email")
+			break
+		}
+	}
+
+	return errs
+}
+
+// Validate runs ValidateOffline, then - if BreachCheck is enabled and Pwned
+// is set - appends a breach count error on top. A failed lookup (e.g. the
+// API is unreachable) is logged and otherwise ignored rather than blocking
+// the caller on a third-party outage.
+func (p *PasswordPolicy) Validate(password string, identifiers ...string) []string {
+	errs := p.ValidateOffline(password, identifiers...)
+	if !p.BreachCheck || p.Pwned == nil {
+		return errs
+	}
+
+	count, err := p.Pwned.CheckBreached(password)
+	if err != nil {
+		log.Printf("utils: pwned passwords check failed, skipping: %v", err)
+		return errs
+	}
+	if count >= p.BreachCountLimit {
+		errs = append(errs, fmt.Sprintf("password has appeared in %d known breaches", count))
+	}
+	return errs
+}
+
+// Strength scores the password like GetPasswordStrength, then downgrades
+// it one level if the breach check (when enabled) finds a match.
+func (p *PasswordPolicy) Strength(password string) PasswordStrength {
+	strength := GetPasswordStrength(password)
+	if !p.BreachCheck || p.Pwned == nil {
+		return strength
+	}
+
+	count, err := p.Pwned.CheckBreached(password)
+	if err != nil {
+		log.Printf("utils: pwned passwords check failed, skipping: %v", err)
+		return strength
+	}
+	if count >= p.BreachCountLimit && strength > PasswordWeak {
+		strength--
+	}
+	return strength
+}
+
+// CheckBreached is a convenience wrapper around Pwned.CheckBreached,
+// returning 0 when no checker is configured instead of requiring every
+// caller to nil-check Pwned itself.
+func (p *PasswordPolicy) CheckBreached(password string) (int, error) {
+	if p.Pwned == nil {
+		return 0, nil
+	}
+	return p.Pwned.CheckBreached(password)
+}
+
+// pwnedRangeChecker is the default PwnedChecker, querying the Pwned
+// Passwords k-anonymity range API.
+type pwnedRangeChecker struct {
+	httpClient *http.Client
+}
+
+// CheckBreached queries the Pwned Passwords k-anonymity range API and
+// returns how many times the password has appeared in a known breach. It
+// SHA-1s the password locally and sends only the 5-char hex prefix, so the
+// API never sees the full hash, let alone the password itself.
+func (c *pwnedRangeChecker) CheckBreached(password string) (int, error) {
+	client := c.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := digest[:5], digest[5:]
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(pwnedPasswordsRangeURL, prefix), nil)
+	if err != nil {
+		return 0, fmt.Errorf("building pwned passwords request: %w", err)
+	}
+	// Add-Padding asks the API to pad the response with decoy suffixes, so
+	// an eavesdropper can't infer the real suffix's popularity from
+	// response size alone.
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("querying pwned passwords api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("pwned passwords api returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		suffixCount := strings.SplitN(scanner.Text(), ":", 2)
+		if len(suffixCount) != 2 {
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(suffixCount[0]), suffix) {
+			continue
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(suffixCount[1]))
+		if err != nil {
+			return 0, fmt.Errorf("parsing breach count: %w", err)
+		}
+		return count, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("reading pwned passwords response: %w", err)
+	}
+
+	return 0, nil
+}